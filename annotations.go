@@ -0,0 +1,38 @@
+package rclonelib
+
+import "fmt"
+
+// Annotate sets the annotation key to value on a transfer. Annotations are
+// free-form metadata for the caller, such as the user or job a transfer
+// belongs to, and are kept by PersistentManager.
+func (m *Manager) Annotate(id, key, value string) error {
+	if key == "" {
+		return &ValidationError{Field: "key", Message: "annotation key cannot be empty"}
+	}
+	if _, exists := m.Get(id); !exists {
+		return fmt.Errorf("%w: %s", ErrTransferNotFound, id)
+	}
+
+	return m.update(id, func(t *Transfer) error {
+		annotations := make(map[string]string, len(t.Annotations)+1)
+		for k, v := range t.Annotations {
+			annotations[k] = v
+		}
+		annotations[key] = value
+		t.Annotations = annotations
+		return nil
+	})
+}
+
+// GetAnnotation returns the annotation key on a transfer, if set
+func (m *Manager) GetAnnotation(id, key string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	t, exists := m.transfers[id]
+	if !exists {
+		return "", false
+	}
+	value, ok := t.Annotations[key]
+	return value, ok
+}
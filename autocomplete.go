@@ -0,0 +1,63 @@
+package rclonelib
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// autocompleteShells are the shells `rclone genautocomplete` supports
+var autocompleteShells = map[string]bool{
+	"bash":       true,
+	"zsh":        true,
+	"fish":       true,
+	"powershell": true,
+}
+
+// GenerateAutoComplete writes rclone's shell completion script for shell
+// ("bash", "zsh", "fish" or "powershell") to outputPath using
+// `rclone genautocomplete`. With an empty outputPath the script is written
+// to stdout rather than rclone's system-wide default location; use
+// GenerateAutoCompleteTo to capture it instead.
+func GenerateAutoComplete(ctx context.Context, shell, outputPath string) error {
+	if outputPath == "" {
+		return GenerateAutoCompleteTo(ctx, shell, os.Stdout)
+	}
+	if err := validateAutocompleteShell(shell); err != nil {
+		return err
+	}
+
+	if _, err := runRclone(ctx, "genautocomplete", shell, outputPath); err != nil {
+		return fmt.Errorf("failed to generate %s completion: %w", shell, err)
+	}
+	return nil
+}
+
+// GenerateAutoCompleteTo writes rclone's completion script for shell to w,
+// for applications that install it themselves, e.g. from a setup wizard
+func GenerateAutoCompleteTo(ctx context.Context, shell string, w io.Writer) error {
+	if err := validateAutocompleteShell(shell); err != nil {
+		return err
+	}
+
+	// "-" makes rclone write the script to stdout
+	output, err := runRclone(ctx, "genautocomplete", shell, "-")
+	if err != nil {
+		return fmt.Errorf("failed to generate %s completion: %w", shell, err)
+	}
+	if _, err := w.Write(output); err != nil {
+		return fmt.Errorf("failed to write %s completion: %w", shell, err)
+	}
+	return nil
+}
+
+func validateAutocompleteShell(shell string) error {
+	if !autocompleteShells[shell] {
+		return &ValidationError{
+			Field:   "shell",
+			Message: fmt.Sprintf("unsupported shell %q (use bash, zsh, fish or powershell)", shell),
+		}
+	}
+	return nil
+}
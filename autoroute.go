@@ -0,0 +1,167 @@
+package rclonelib
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SizeInfo describes the size of a file or directory
+type SizeInfo struct {
+	// Size is the total size in bytes, of all files for a directory
+	Size int64
+	// Count is the number of files, 1 for a file
+	Count int64
+	IsDir bool
+}
+
+// sizeInfoTTL is how long GetSizeInfo reuses a result
+const sizeInfoTTL = time.Minute
+
+// sizeInfoCache holds recent GetSizeInfo results by path, so options built
+// repeatedly for the same source don't stat it every time
+var sizeInfoCache struct {
+	mu      sync.Mutex
+	entries map[string]sizeInfoEntry
+}
+
+type sizeInfoEntry struct {
+	info    SizeInfo
+	expires time.Time
+}
+
+// GetSizeInfo returns the size of the local or remote file or directory at
+// path, using `rclone lsjson --stat` and, for directories, `rclone size`.
+// Results are cached for a minute.
+func GetSizeInfo(ctx context.Context, path string) (*SizeInfo, error) {
+	sizeInfoCache.mu.Lock()
+	entry, ok := sizeInfoCache.entries[path]
+	sizeInfoCache.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		info := entry.info
+		return &info, nil
+	}
+
+	stat, err := statFile(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	info := SizeInfo{Size: stat.Size, Count: 1}
+	if stat.IsDir {
+		output, err := runRclone(ctx, "size", "--json", path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get size of %s: %w", path, err)
+		}
+		var size struct {
+			Count int64 `json:"count"`
+			Bytes int64 `json:"bytes"`
+		}
+		if err := json.Unmarshal(output, &size); err != nil {
+			return nil, fmt.Errorf("failed to parse rclone size output: %w", err)
+		}
+		info = SizeInfo{Size: size.Bytes, Count: size.Count, IsDir: true}
+	}
+
+	sizeInfoCache.mu.Lock()
+	if sizeInfoCache.entries == nil {
+		sizeInfoCache.entries = make(map[string]sizeInfoEntry)
+	}
+	sizeInfoCache.entries[path] = sizeInfoEntry{info: info, expires: time.Now().Add(sizeInfoTTL)}
+	sizeInfoCache.mu.Unlock()
+
+	return &info, nil
+}
+
+// AutoRouteOptions sets the size thresholds AutoRoute chooses between
+type AutoRouteOptions struct {
+	// SingleFileCutoff: files smaller than this are copied with copyto
+	SingleFileCutoff int64
+	// MultiPartCutoff: files larger than this are copied with multi-thread
+	// transfers. 0 leaves rclone's own --multi-thread-cutoff in place.
+	MultiPartCutoff int64
+}
+
+// AutoRoute picks the command for copying src into the directory dst from
+// the size of src: copyto for files under SingleFileCutoff and copy for
+// everything else, including directories. If src can't be sized it falls
+// back to copy. Use TransferOptions.WithAutoRoute to also apply the
+// matching destination path and multi-thread settings.
+func AutoRoute(ctx context.Context, src, dst string, opts AutoRouteOptions) RcloneCommand {
+	info, err := GetSizeInfo(ctx, src)
+	if err != nil {
+		return RcloneCopy
+	}
+	return opts.route(info)
+}
+
+func (o AutoRouteOptions) route(info *SizeInfo) RcloneCommand {
+	if !info.IsDir && info.Size < o.SingleFileCutoff {
+		return RcloneCopyTo
+	}
+	return RcloneCopy
+}
+
+// multiThread reports whether info is a file large enough for multi-thread
+// transfers
+func (o AutoRouteOptions) multiThread(info *SizeInfo) bool {
+	return !info.IsDir && o.MultiPartCutoff > 0 && info.Size > o.MultiPartCutoff
+}
+
+// WithAutoRoute makes Build choose the command with AutoRoute, treating the
+// destination as a directory. When copyto is chosen the source's file name
+// is appended to the destination so the file lands in the same place, and
+// files over MultiPartCutoff get --multi-thread-cutoff unless it is already
+// set. Build sizes the source once, using opts.Context if set.
+func (t *TransferOptions) WithAutoRoute(opts AutoRouteOptions) *TransferOptions {
+	if opts.SingleFileCutoff < 0 || opts.MultiPartCutoff < 0 {
+		t.errs = append(t.errs, &ValidationError{Field: "auto_route", Message: "cutoffs cannot be negative"})
+		return t
+	}
+	t.autoRoute = &opts
+	return t
+}
+
+// applyAutoRoute sets the command, destination and flags chosen by
+// WithAutoRoute. The size lookup is kept on the builder so later Builds
+// don't repeat it.
+func (t *TransferOptions) applyAutoRoute(opts RcloneOptions) RcloneOptions {
+	if t.routeInfo == nil {
+		ctx := opts.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		info, err := GetSizeInfo(ctx, opts.Source)
+		if err != nil {
+			opts.Command = RcloneCopy
+			return opts
+		}
+		t.routeInfo = info
+	}
+
+	opts.Command = t.autoRoute.route(t.routeInfo)
+	if opts.Command == RcloneCopyTo {
+		opts.Destination = childPath(opts.Destination, baseName(opts.Source))
+	}
+	if t.autoRoute.multiThread(t.routeInfo) && !hasFlag(opts.Flags, "--multi-thread-cutoff") {
+		flags := make([]string, 0, len(opts.Flags)+2)
+		flags = append(flags, opts.Flags...)
+		opts.Flags = append(flags, "--multi-thread-cutoff", strconv.FormatInt(t.autoRoute.MultiPartCutoff, 10)+"B")
+	}
+	return opts
+}
+
+// baseName returns the last element of a local or remote path
+func baseName(p string) string {
+	if IsRemotePath(p) {
+		_, rest := SplitRemotePath(p)
+		return path.Base(strings.TrimSuffix(rest, "/"))
+	}
+	return filepath.Base(p)
+}
@@ -0,0 +1,151 @@
+package rclonelib
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Bounds for the --transfers value suggested by AutoTuneTransfers
+const (
+	minTunedTransfers = 1
+	maxTunedTransfers = 32
+)
+
+// AutoTuneTransfers benchmarks remote with TestRemoteSpeed and suggests a
+// --transfers value in [1, 32]. High-latency remotes get more parallel
+// transfers to keep the link busy; remotes where a single stream is already
+// fast get fewer.
+func AutoTuneTransfers(ctx context.Context, remote string) (int, error) {
+	result, err := TestRemoteSpeed(ctx, remote)
+	if err != nil {
+		return 0, err
+	}
+	return suggestTransfers(result), nil
+}
+
+// suggestTransfers turns a speed test into a --transfers value. It allows
+// roughly one transfer per 50ms of round trip, which is what it takes to
+// hide per-file request overhead, then halves that when a single stream
+// already moves more than 50 MiB/s since extra streams then mostly contend
+// for the same bandwidth.
+func suggestTransfers(r *SpeedTestResult) int {
+	n := int(r.Latency / (50 * time.Millisecond))
+
+	stream := r.UploadMBps
+	if r.DownloadMBps > stream {
+		stream = r.DownloadMBps
+	}
+	if stream > 50 {
+		n /= 2
+	}
+
+	if n < minTunedTransfers {
+		n = minTunedTransfers
+	}
+	if n > maxTunedTransfers {
+		n = maxTunedTransfers
+	}
+	return n
+}
+
+// SetAutoTune enables or disables --transfers auto-tuning. When enabled,
+// Execute benchmarks each destination remote once, before its first
+// transfer, with AutoTuneTransfers and adds the suggested --transfers to any
+// transfer that doesn't set one itself. Downloads to local disk are left
+// alone. Results are cached per remote for the life of the manager; a
+// failed benchmark leaves rclone's default in place.
+func (m *Manager) SetAutoTune(enabled bool) {
+	m.autoTuneMu.Lock()
+	defer m.autoTuneMu.Unlock()
+	m.autoTune = enabled
+}
+
+// applyAutoTune returns opts with a tuned --transfers flag added, if
+// auto-tuning is enabled and opts doesn't already choose a value
+func (m *Manager) applyAutoTune(ctx context.Context, opts RcloneOptions) RcloneOptions {
+	if hasFlag(opts.Flags, "--transfers") {
+		return opts
+	}
+
+	remote, dir := transferRemote(opts)
+	if remote == "" {
+		return opts
+	}
+
+	m.autoTuneMu.Lock()
+	if !m.autoTune {
+		m.autoTuneMu.Unlock()
+		return opts
+	}
+	tuned, ok := m.tuned[remote]
+	if !ok {
+		tuned = &tunedTransfers{}
+		if m.tuned == nil {
+			m.tuned = make(map[string]*tunedTransfers)
+		}
+		m.tuned[remote] = tuned
+	}
+	m.autoTuneMu.Unlock()
+
+	// Benchmark outside the lock so transfers to other remotes aren't held
+	// up; concurrent transfers to this remote wait for the one run.
+	n := tuned.get(func() int {
+		// Failures are cached as 0 too, so a broken remote isn't
+		// re-benchmarked for every transfer.
+		n, _ := AutoTuneTransfers(ctx, dir)
+		return n
+	})
+	if n <= 0 {
+		return opts
+	}
+
+	flags := make([]string, 0, len(opts.Flags)+2)
+	flags = append(flags, opts.Flags...)
+	opts.Flags = append(flags, "--transfers", strconv.Itoa(n))
+	return opts
+}
+
+// tunedTransfers is the cached --transfers suggestion for one remote
+type tunedTransfers struct {
+	once sync.Once
+	n    int
+}
+
+// get returns the cached value, calling tune to compute it the first time
+func (t *tunedTransfers) get(tune func() int) int {
+	t.once.Do(func() { t.n = tune() })
+	return t.n
+}
+
+// transferRemote returns the name of a transfer's destination remote and a
+// directory on it that can be written to for benchmarking. Both are empty
+// when the destination is local: benchmarking the source instead would
+// leave a test file in it.
+func transferRemote(opts RcloneOptions) (remote, dir string) {
+	if IsRemotePath(opts.Destination) {
+		remote, _ = SplitRemotePath(opts.Destination)
+		dir = opts.Destination
+		if opts.Command == RcloneCopyTo || opts.Command == RcloneMoveTo {
+			dir = parentPath(opts.Destination)
+		}
+		if dir == "" {
+			dir = remote + ":"
+		}
+		return remote, dir
+	}
+	return "", ""
+}
+
+// hasFlag reports whether flags sets name, either as "--name value" or
+// "--name=value"
+func hasFlag(flags []string, name string) bool {
+	for _, f := range flags {
+		if f == name || strings.HasPrefix(f, name+"=") {
+			return true
+		}
+	}
+	return false
+}
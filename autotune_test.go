@@ -0,0 +1,25 @@
+package rclonelib
+
+import "testing"
+
+func TestTransferRemote(t *testing.T) {
+	tests := []struct {
+		opts        RcloneOptions
+		remote, dir string
+	}{
+		{RcloneOptions{Command: RcloneCopy, Source: "/src", Destination: "s3:bucket/dir"}, "s3", "s3:bucket/dir"},
+		{RcloneOptions{Command: RcloneCopyTo, Source: "/a.txt", Destination: "s3:bucket/a.txt"}, "s3", "s3:bucket"},
+		{RcloneOptions{Command: RcloneCopy, Source: "gdrive:docs", Destination: "s3:"}, "s3", "s3:"},
+		// Downloads aren't benchmarked: that would write into the source
+		{RcloneOptions{Command: RcloneCopy, Source: "s3:bucket", Destination: "/dst"}, "", ""},
+		{RcloneOptions{Command: RcloneCopy, Source: "/src", Destination: "/dst"}, "", ""},
+	}
+
+	for _, tt := range tests {
+		remote, dir := transferRemote(tt.opts)
+		if remote != tt.remote || dir != tt.dir {
+			t.Errorf("transferRemote(%s -> %s) = %q, %q; want %q, %q",
+				tt.opts.Source, tt.opts.Destination, remote, dir, tt.remote, tt.dir)
+		}
+	}
+}
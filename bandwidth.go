@@ -0,0 +1,98 @@
+package rclonelib
+
+import (
+	"strings"
+	"sync"
+)
+
+// BandwidthAccounting totals the bytes moved by a Manager's transfers per
+// destination remote and per source. Register it with Manager.AddNotifier.
+//
+// Transfers to local destinations are accounted under the empty remote name.
+type BandwidthAccounting struct {
+	mu       sync.Mutex
+	lastSeen map[string]int64 // transfer ID -> BytesCopied at last update
+	byRemote map[string]int64
+	bySource map[string]int64
+	total    int64
+}
+
+// NewBandwidthAccounting creates an empty BandwidthAccounting
+func NewBandwidthAccounting() *BandwidthAccounting {
+	return &BandwidthAccounting{
+		lastSeen: make(map[string]int64),
+		byRemote: make(map[string]int64),
+		bySource: make(map[string]int64),
+	}
+}
+
+// Notify implements Notifier by accounting for the bytes copied since the
+// transfer's previous update
+func (b *BandwidthAccounting) Notify(t Transfer) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delta := t.BytesCopied - b.lastSeen[t.ID]
+	if delta < 0 {
+		// Progress went backwards, so rclone restarted the transfer (e.g. a
+		// retry). The bytes it sends again still cross the wire.
+		delta = t.BytesCopied
+	}
+	b.lastSeen[t.ID] = t.BytesCopied
+
+	if delta == 0 {
+		return
+	}
+
+	remote := ""
+	if IsRemotePath(t.Destination) {
+		remote, _ = SplitRemotePath(t.Destination)
+	}
+	b.byRemote[remote] += delta
+	b.bySource[t.Source] += delta
+	b.total += delta
+}
+
+// BytesForRemote returns the bytes transferred to the named remote
+func (b *BandwidthAccounting) BytesForRemote(remote string) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.byRemote[strings.TrimSuffix(remote, ":")]
+}
+
+// BytesForSource returns the bytes transferred from the given source path
+func (b *BandwidthAccounting) BytesForSource(source string) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.bySource[source]
+}
+
+// TotalBytes returns the bytes transferred across all remotes
+func (b *BandwidthAccounting) TotalBytes() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.total
+}
+
+// Report returns a copy of the per-remote totals
+func (b *BandwidthAccounting) Report() map[string]int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	report := make(map[string]int64, len(b.byRemote))
+	for remote, n := range b.byRemote {
+		report[remote] = n
+	}
+	return report
+}
+
+// Reset clears all totals. Transfers already in flight continue to be
+// accounted from their current position.
+func (b *BandwidthAccounting) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.byRemote = make(map[string]int64)
+	b.bySource = make(map[string]int64)
+	b.total = 0
+}
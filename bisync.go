@@ -0,0 +1,141 @@
+package rclonelib
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BisyncOptions configures Bisync
+type BisyncOptions struct {
+	// Resync rebuilds the listings from scratch, copying in both directions.
+	// Required on the first run; Bisync sets it automatically when rclone
+	// reports it is needed.
+	Resync bool
+	// ResyncMode decides which side wins during a resync (--resync-mode):
+	// "path1", "path2", "newer", "older", "larger" or "smaller"
+	ResyncMode string
+	// NoAutoResync disables the automatic --resync retry on first run
+	NoAutoResync bool
+	// CheckAccess aborts unless RCLONE_TEST files match on both sides
+	// (--check-access)
+	CheckAccess bool
+	// MaxDelete aborts if more than this percentage of files would be
+	// deleted (--max-delete, rclone default 50)
+	MaxDelete int
+	// Force bypasses the MaxDelete safety check (--force)
+	Force  bool
+	DryRun bool
+	// Flags are additional flags to pass to rclone
+	Flags []string
+}
+
+// BisyncResult summarises a bisync run
+type BisyncResult struct {
+	FilesToPath1 int
+	FilesToPath2 int
+	Deleted      int
+	// Conflicts counts files changed on both sides; rclone keeps both copies
+	Conflicts int
+	// Resynced is set if a --resync run was made
+	Resynced   bool
+	Successful bool
+}
+
+// Bisync synchronises path1 and path2 in both directions using `rclone
+// bisync`. The first bisync between two paths needs --resync; unless
+// opts.NoAutoResync is set, Bisync detects that case (rclone can't find
+// prior listings and there are no .lst-err files from a failed run) and
+// re-runs with it. Every other failure is returned as it is, since a resync
+// after a critical error can resurrect deleted files or lose changes.
+func Bisync(ctx context.Context, path1, path2 string, opts BisyncOptions) (*BisyncResult, error) {
+	if path1 == "" || path2 == "" {
+		return nil, &ValidationError{Field: "paths", Message: "both paths are required"}
+	}
+
+	output, err := runRcloneCombined(ctx, bisyncArgs(path1, path2, opts)...)
+	if err != nil && !opts.Resync && !opts.NoAutoResync && needsResync(string(output)) {
+		opts.Resync = true
+		output, err = runRcloneCombined(ctx, bisyncArgs(path1, path2, opts)...)
+	}
+
+	result := parseBisyncOutput(string(output))
+	result.Resynced = opts.Resync
+	if err != nil {
+		return result, fmt.Errorf("failed to bisync %s and %s: %w", path1, path2, err)
+	}
+	return result, nil
+}
+
+// bisyncArgs builds the rclone arguments for a bisync run
+func bisyncArgs(path1, path2 string, opts BisyncOptions) []string {
+	args := []string{string(RcloneBisync), path1, path2, "-v"}
+	if opts.Resync {
+		args = append(args, "--resync")
+		if opts.ResyncMode != "" {
+			args = append(args, "--resync-mode", opts.ResyncMode)
+		}
+	}
+	if opts.CheckAccess {
+		args = append(args, "--check-access")
+	}
+	if opts.MaxDelete > 0 {
+		args = append(args, "--max-delete", strconv.Itoa(opts.MaxDelete))
+	}
+	if opts.Force {
+		args = append(args, "--force")
+	}
+	if opts.DryRun {
+		args = append(args, "--dry-run")
+	}
+	return append(args, opts.Flags...)
+}
+
+// priorListingRegex matches the listing files bisync says it looked for,
+// e.g. "Path1: /home/u/.cache/rclone/bisync/a..b.path1.lst"
+var priorListingRegex = regexp.MustCompile(`Path[12]:\s+(.+\.lst)\s*$`)
+
+// needsResync reports whether a failed bisync is a first run between its
+// paths. rclone says "must run --resync" after every critical error, so
+// that isn't trusted: only missing prior listings are, and only if bisync
+// didn't leave .lst-err files behind, which mark an earlier failed run.
+func needsResync(output string) bool {
+	if !strings.Contains(output, "cannot find prior Path1 or Path2 listings") ||
+		strings.Contains(output, ".lst-err") {
+		return false
+	}
+	for _, line := range strings.Split(output, "\n") {
+		m := priorListingRegex.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if _, err := os.Stat(m[1] + "-err"); err == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// parseBisyncOutput tallies the actions bisync logged, e.g.
+// "- Path1    Queue copy to Path2    - path2/file.txt"
+func parseBisyncOutput(output string) *BisyncResult {
+	result := &BisyncResult{}
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.Contains(line, "Queue copy to Path1"):
+			result.FilesToPath1++
+		case strings.Contains(line, "Queue copy to Path2"):
+			result.FilesToPath2++
+		case strings.Contains(line, "Queue delete"):
+			result.Deleted++
+		case strings.Contains(line, "New or changed in both paths"):
+			result.Conflicts++
+		case strings.Contains(line, "Bisync successful"):
+			result.Successful = true
+		}
+	}
+	return result
+}
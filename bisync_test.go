@@ -0,0 +1,58 @@
+package rclonelib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNeedsResync(t *testing.T) {
+	dir := t.TempDir()
+	listing1 := filepath.Join(dir, "a..b.path1.lst")
+	listing2 := filepath.Join(dir, "a..b.path2.lst")
+	firstRun := "2024/01/02 15:04:05 ERROR : Bisync critical error: cannot find prior Path1 or Path2 listings, likely due to critical error on prior run\n" +
+		"Tip: here are the filenames we were looking for. Do they exist? \n" +
+		"Path1: " + listing1 + "\n" +
+		"Path2: " + listing2 + "\n" +
+		"2024/01/02 15:04:05 ERROR : Bisync aborted. Must run --resync to recover.\n"
+
+	if !needsResync(firstRun) {
+		t.Error("first run should need a resync")
+	}
+
+	otherCritical := "ERROR : Bisync critical error: path1 listing has duplicate entries\n" +
+		"ERROR : Bisync aborted. Must run --resync to recover.\n"
+	if needsResync(otherCritical) {
+		t.Error("other critical errors must not trigger a resync")
+	}
+	if needsResync("ERROR : Bisync aborted. Error is retryable without --resync due to --resilient mode.\n") {
+		t.Error("retryable error must not trigger a resync")
+	}
+
+	// A failed earlier run leaves its listings renamed to .lst-err
+	if err := os.WriteFile(listing1+"-err", nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if needsResync(firstRun) {
+		t.Error("listings left by a failed run must not trigger a resync")
+	}
+}
+
+func TestParseBisyncOutput(t *testing.T) {
+	output := "INFO  : - Path1    Queue copy to Path2    - path2/new.txt\n" +
+		"INFO  : - Path1    Queue copy to Path2    - path2/changed.txt\n" +
+		"INFO  : - Path2    Queue copy to Path1    - path1/other.txt\n" +
+		"INFO  : - Path2    Queue delete           - path2/gone.txt\n" +
+		"NOTICE: - WARNING  New or changed in both paths - both.txt\n" +
+		"INFO  : Bisync successful\n"
+
+	result := parseBisyncOutput(output)
+	if result.FilesToPath2 != 2 || result.FilesToPath1 != 1 || result.Deleted != 1 ||
+		result.Conflicts != 1 || !result.Successful {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	if result := parseBisyncOutput("ERROR : Bisync aborted.\n"); result.Successful {
+		t.Errorf("aborted run reported successful: %+v", result)
+	}
+}
@@ -0,0 +1,96 @@
+package rclonelib
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// CopySpec describes one transfer in a BulkCopy
+type CopySpec struct {
+	ID  string
+	Src string
+	Dst string
+}
+
+// TransferError is the error a single transfer failed with
+type TransferError struct {
+	ID  string
+	Err error
+}
+
+func (e TransferError) Error() string {
+	return fmt.Sprintf("transfer %s: %v", e.ID, e.Err)
+}
+
+func (e TransferError) Unwrap() error {
+	return e.Err
+}
+
+// BulkCopyResult summarises a BulkCopy
+type BulkCopyResult struct {
+	Completed int
+	Failed    int
+	// Errors holds one entry per failed transfer, in the order of the specs
+	Errors   []TransferError
+	Duration time.Duration
+}
+
+// BulkCopy runs every transfer in specs with at most maxConcurrent running
+// at once, retrying each according to retryCfg, and waits for them all to
+// finish. opts supplies the flags and command (default: copy) shared by all
+// transfers; Source and Destination are taken from each spec and ctx
+// replaces opts.Context. Individual failures are reported in the result;
+// the error is only non-nil if the specs are invalid.
+func BulkCopy(ctx context.Context, specs []CopySpec, maxConcurrent int, opts RcloneOptions, retryCfg RetryConfig) (*BulkCopyResult, error) {
+	if maxConcurrent <= 0 {
+		return nil, &ValidationError{Field: "maxConcurrent", Message: "must be at least 1"}
+	}
+	seen := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		if spec.ID == "" {
+			return nil, &ValidationError{Field: "id", Message: "transfer ID cannot be empty"}
+		}
+		if seen[spec.ID] {
+			return nil, &ValidationError{Field: "id", Message: fmt.Sprintf("duplicate transfer ID %q", spec.ID)}
+		}
+		seen[spec.ID] = true
+	}
+
+	if opts.Command == "" {
+		opts.Command = RcloneCopy
+	}
+	opts.Context = ctx
+
+	manager := NewManager()
+	pool := NewPool(ctx, manager, NewExecutor(manager), maxConcurrent)
+
+	start := time.Now()
+	for _, spec := range specs {
+		manager.Add(spec.ID, spec.Src, spec.Dst)
+		specOpts := opts
+		specOpts.Source = spec.Src
+		specOpts.Destination = spec.Dst
+		if err := pool.Submit(spec.ID, specOpts, retryCfg); err != nil {
+			return nil, err
+		}
+	}
+	pool.Wait()
+
+	result := &BulkCopyResult{Duration: time.Since(start)}
+	for _, spec := range specs {
+		t, _ := manager.Get(spec.ID)
+		switch {
+		case t.Status == StatusCompleted:
+			result.Completed++
+		case t.Error != nil:
+			result.Failed++
+			result.Errors = append(result.Errors, TransferError{ID: spec.ID, Err: t.Error})
+		default:
+			// Never started because ctx was cancelled
+			result.Failed++
+			result.Errors = append(result.Errors, TransferError{ID: spec.ID, Err: ctx.Err()})
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,166 @@
+package rclonelib
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Status symbols written by `rclone check --combined`
+const (
+	CheckMatch        = '=' // identical in source and destination
+	CheckMissingOnDst = '-' // only in the source
+	CheckMissingOnSrc = '+' // only in the destination
+	CheckDiffer       = '*' // in both, but different
+	CheckError        = '!' // could not be checked
+)
+
+// CheckOptions configures Executor.Check
+type CheckOptions struct {
+	Source      string
+	Destination string
+	// OneWay only reports files missing from the destination (--one-way)
+	OneWay bool
+	// Download compares file contents rather than hashes (--download)
+	Download bool
+	// SizeOnly compares sizes only (--size-only)
+	SizeOnly bool
+	// CombinedOutput keeps rclone's per-file report (--combined) at this
+	// path, replacing any existing file. When empty a temporary file is
+	// used and removed afterwards.
+	CombinedOutput string
+	// Flags are additional flags to pass to rclone
+	Flags []string
+	// Context allows cancellation of the check
+	Context context.Context
+}
+
+// CombinedLine is one entry of `rclone check --combined` output
+type CombinedLine struct {
+	// Status is one of CheckMatch, CheckMissingOnDst, CheckMissingOnSrc,
+	// CheckDiffer or CheckError
+	Status rune
+	Path   string
+}
+
+// CheckResult summarises a comparison of two paths
+type CheckResult struct {
+	Matches      int
+	Differences  int
+	MissingOnDst int
+	MissingOnSrc int
+	Errors       int
+
+	CombinedLines []CombinedLine
+}
+
+// InSync reports whether the check found no differences, missing files or
+// errors
+func (r *CheckResult) InSync() bool {
+	return r.Differences == 0 && r.MissingOnDst == 0 && r.MissingOnSrc == 0 && r.Errors == 0
+}
+
+// Check compares opts.Source with opts.Destination using `rclone check` and
+// reports the outcome per file. Differences are returned in the result
+// rather than as an error; an error means the check itself couldn't run.
+func (e *Executor) Check(opts CheckOptions) (*CheckResult, error) {
+	if opts.Source == "" || opts.Destination == "" {
+		return nil, &ValidationError{Field: "paths", Message: "source and destination are required"}
+	}
+
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	combined := opts.CombinedOutput
+	if combined == "" {
+		f, err := os.CreateTemp("", "rclonelib-check-*.txt")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create check report file: %w", err)
+		}
+		f.Close()
+		combined = f.Name()
+		defer os.Remove(combined)
+	} else if err := os.Remove(combined); err != nil && !os.IsNotExist(err) {
+		// A report left over from an earlier run would otherwise be read
+		// back as this run's result if rclone fails before writing one.
+		return nil, fmt.Errorf("failed to remove old check report: %w", err)
+	}
+
+	args := []string{string(RcloneCheck), opts.Source, opts.Destination, "--combined", combined}
+	if opts.OneWay {
+		args = append(args, "--one-way")
+	}
+	if opts.Download {
+		args = append(args, "--download")
+	}
+	if opts.SizeOnly {
+		args = append(args, "--size-only")
+	}
+	args = append(args, opts.Flags...)
+
+	// rclone check exits non-zero when it finds differences, so only treat
+	// the exit status as fatal if no report was written.
+	_, runErr := runRclone(ctx, args...)
+
+	f, err := os.Open(combined)
+	if err != nil {
+		if runErr != nil {
+			return nil, fmt.Errorf("failed to check %s against %s: %w", opts.Source, opts.Destination, runErr)
+		}
+		return nil, fmt.Errorf("failed to open check report: %w", err)
+	}
+	defer f.Close()
+
+	result, err := ParseCombinedCheckOutput(f)
+	if err != nil {
+		return nil, err
+	}
+	if runErr != nil && len(result.CombinedLines) == 0 {
+		return nil, fmt.Errorf("failed to check %s against %s: %w", opts.Source, opts.Destination, runErr)
+	}
+
+	return result, nil
+}
+
+// ParseCombinedCheckOutput parses the report written by `rclone check
+// --combined`, one "<symbol> <path>" line per file, and tallies the
+// results. Unrecognised lines are skipped.
+func ParseCombinedCheckOutput(r io.Reader) (*CheckResult, error) {
+	result := &CheckResult{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) < 3 || line[1] != ' ' {
+			continue
+		}
+
+		status := rune(line[0])
+		switch status {
+		case CheckMatch:
+			result.Matches++
+		case CheckMissingOnDst:
+			result.MissingOnDst++
+		case CheckMissingOnSrc:
+			result.MissingOnSrc++
+		case CheckDiffer:
+			result.Differences++
+		case CheckError:
+			result.Errors++
+		default:
+			continue
+		}
+
+		result.CombinedLines = append(result.CombinedLines, CombinedLine{Status: status, Path: line[2:]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read check report: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,90 @@
+package rclonelib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseCombinedCheckOutput(t *testing.T) {
+	output := "= same.txt\n" +
+		"= dir/also same.txt\n" +
+		"- only-in-src.txt\n" +
+		"+ only-in-dst.txt\n" +
+		"* changed.bin\n" +
+		"! unreadable.dat\n" +
+		"\n" +
+		"garbage\n"
+
+	result, err := ParseCombinedCheckOutput(strings.NewReader(output))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Matches != 2 || result.MissingOnDst != 1 || result.MissingOnSrc != 1 ||
+		result.Differences != 1 || result.Errors != 1 {
+		t.Errorf("unexpected counts: %+v", result)
+	}
+	if result.InSync() {
+		t.Error("expected InSync to be false")
+	}
+
+	if len(result.CombinedLines) != 6 {
+		t.Fatalf("expected 6 lines, got %d", len(result.CombinedLines))
+	}
+	if got := result.CombinedLines[1]; got.Status != CheckMatch || got.Path != "dir/also same.txt" {
+		t.Errorf("unexpected line: %+v", got)
+	}
+	if got := result.CombinedLines[4]; got.Status != CheckDiffer || got.Path != "changed.bin" {
+		t.Errorf("unexpected line: %+v", got)
+	}
+}
+
+func TestParseCombinedCheckOutput_InSync(t *testing.T) {
+	result, err := ParseCombinedCheckOutput(strings.NewReader("= a\n= b\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.InSync() {
+		t.Errorf("expected InSync, got %+v", result)
+	}
+}
+
+func TestVerify_InvalidOptionsAndClassification(t *testing.T) {
+	m := NewManager()
+	e := NewExecutor(m)
+
+	_, err := e.Verify(context.Background(), "/src", "remote:dst", VerifyOptions{ChecksumOnly: true, SizeOnly: true, TransferID: "v1"})
+	if err == nil {
+		t.Fatal("expected an error for ChecksumOnly with SizeOnly")
+	}
+	if _, exists := m.Get("v1"); exists {
+		t.Error("invalid options should not add a transfer")
+	}
+
+	verr := &ClassifiedError{Type: ErrorTypeVerificationFailed, Err: errors.New("1 differ")}
+	if got := ClassifyError(fmt.Errorf("audit: %w", verr)); got.Type != ErrorTypeVerificationFailed {
+		t.Errorf("ClassifyError type = %s, want %s", got.Type, ErrorTypeVerificationFailed)
+	}
+}
+
+func TestCheck_IgnoresStaleCombinedOutput(t *testing.T) {
+	fakeRclone(t, "echo 'directory not found' >&2\nexit 3\n")
+	report := filepath.Join(t.TempDir(), "report.txt")
+	if err := os.WriteFile(report, []byte("= old.txt\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewExecutor(NewManager()).Check(CheckOptions{
+		Source:         "/src",
+		Destination:    "remote:dst",
+		CombinedOutput: report,
+	})
+	if err == nil {
+		t.Fatalf("expected rclone's failure, got %+v", result)
+	}
+}
@@ -0,0 +1,59 @@
+package rclonelib
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// hashTypes are the hash names rclone knows, as used by --hash-type and
+// reported by `rclone backend features`
+var hashTypes = map[string]bool{
+	"md5":       true,
+	"sha1":      true,
+	"sha256":    true,
+	"sha512":    true,
+	"whirlpool": true,
+	"crc32":     true,
+	"blake3":    true,
+	"xxh3":      true,
+	"xxh128":    true,
+	"dropbox":   true,
+	"hidrive":   true,
+	"mailru":    true,
+	"quickxor":  true,
+}
+
+// SupportedChecksums returns the hash types remote (e.g. "mys3:" or a local
+// path) can produce, lower-cased and in rclone's order, using `rclone
+// backend features`. An empty result means the backend supports no hashes
+// and transfers can only be compared by size and modification time.
+func SupportedChecksums(ctx context.Context, remote string) ([]string, error) {
+	if remote == "" {
+		return nil, &ValidationError{Field: "remote", Message: "remote cannot be empty"}
+	}
+
+	output, err := runRclone(ctx, "backend", "features", remote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get features of %s: %w", remote, err)
+	}
+	return parseSupportedChecksums(output)
+}
+
+// parseSupportedChecksums extracts the hash list from `rclone backend
+// features` JSON
+func parseSupportedChecksums(output []byte) ([]string, error) {
+	var features struct {
+		Hashes []string `json:"Hashes"`
+	}
+	if err := json.Unmarshal(output, &features); err != nil {
+		return nil, fmt.Errorf("failed to parse backend features: %w", err)
+	}
+
+	hashes := make([]string, 0, len(features.Hashes))
+	for _, h := range features.Hashes {
+		hashes = append(hashes, strings.ToLower(h))
+	}
+	return hashes, nil
+}
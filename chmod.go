@@ -0,0 +1,161 @@
+package rclonelib
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Chmod changes the permissions of path, and with recursive set everything
+// beneath it. mode is either octal ("0755", "644") or symbolic ("u+x",
+// "go-w", "a=r", "u+rw,g-w").
+//
+// rclone has no chmod command, so only local paths are supported; remote
+// paths return ErrChmodUnsupported. Backends such as SFTP that preserve
+// permissions pick them up from local files when uploading with --metadata.
+func Chmod(ctx context.Context, path, mode string, recursive bool) error {
+	if path == "" {
+		return &ValidationError{Field: "path", Message: "path cannot be empty"}
+	}
+	if _, err := parseFileMode(mode, 0); err != nil {
+		return err
+	}
+	if IsRemotePath(path) {
+		return fmt.Errorf("%w: %s", ErrChmodUnsupported, path)
+	}
+
+	apply := func(p string, current fs.FileMode) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		newMode, err := parseFileMode(mode, current.Perm())
+		if err != nil {
+			return err
+		}
+		// Keep setuid/setgid/sticky, which the mode string doesn't cover.
+		special := current & (fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky)
+		if err := os.Chmod(p, newMode|special); err != nil {
+			return fmt.Errorf("failed to chmod %s: %w", p, err)
+		}
+		return nil
+	}
+
+	if !recursive {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+		return apply(path, info.Mode())
+	}
+
+	return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// Like chmod -R, leave symlinks alone: os.Chmod would follow them,
+		// possibly out of the tree, and WalkDir reports the link's own mode
+		// rather than its target's.
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return apply(p, info.Mode())
+	})
+}
+
+// Chown changes the owner and group of a local path. Pass -1 for uid or gid
+// to leave it unchanged. Remote paths return ErrChmodUnsupported.
+func Chown(ctx context.Context, path string, uid, gid int) error {
+	if path == "" {
+		return &ValidationError{Field: "path", Message: "path cannot be empty"}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if IsRemotePath(path) {
+		return fmt.Errorf("%w: %s", ErrChmodUnsupported, path)
+	}
+
+	if err := os.Chown(path, uid, gid); err != nil {
+		return fmt.Errorf("failed to chown %s: %w", path, err)
+	}
+	return nil
+}
+
+// parseFileMode applies a Unix mode string to current and returns the
+// resulting permission bits. Octal modes replace current outright;
+// symbolic clauses modify it.
+func parseFileMode(mode string, current fs.FileMode) (fs.FileMode, error) {
+	mode = strings.TrimSpace(mode)
+	if mode == "" {
+		return 0, &ValidationError{Field: "mode", Message: "mode cannot be empty"}
+	}
+
+	if mode[0] >= '0' && mode[0] <= '7' {
+		n, err := strconv.ParseUint(mode, 8, 32)
+		if err != nil || n > 0777 {
+			return 0, &ValidationError{Field: "mode", Message: fmt.Sprintf("invalid octal mode: %s", mode)}
+		}
+		return fs.FileMode(n), nil
+	}
+
+	result := current.Perm()
+	for _, clause := range strings.Split(mode, ",") {
+		i := 0
+		var who fs.FileMode
+		for ; i < len(clause) && strings.IndexByte("ugoa", clause[i]) >= 0; i++ {
+			switch clause[i] {
+			case 'u':
+				who |= 0700
+			case 'g':
+				who |= 0070
+			case 'o':
+				who |= 0007
+			case 'a':
+				who |= 0777
+			}
+		}
+		if who == 0 {
+			who = 0777
+		}
+
+		if i >= len(clause) || strings.IndexByte("+-=", clause[i]) < 0 {
+			return 0, &ValidationError{Field: "mode", Message: fmt.Sprintf("invalid symbolic mode: %s", clause)}
+		}
+		op := clause[i]
+		i++
+
+		var perms fs.FileMode
+		for ; i < len(clause); i++ {
+			switch clause[i] {
+			case 'r':
+				perms |= 0444
+			case 'w':
+				perms |= 0222
+			case 'x':
+				perms |= 0111
+			default:
+				return 0, &ValidationError{Field: "mode", Message: fmt.Sprintf("invalid permission %q in %s", clause[i], clause)}
+			}
+		}
+		perms &= who
+
+		switch op {
+		case '+':
+			result |= perms
+		case '-':
+			result &^= perms
+		case '=':
+			result = result&^who | perms
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,75 @@
+package rclonelib
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestParseFileMode(t *testing.T) {
+	tests := []struct {
+		mode    string
+		current fs.FileMode
+		want    fs.FileMode
+	}{
+		{"0755", 0600, 0755},
+		{"644", 0777, 0644},
+		{"u+x", 0644, 0744},
+		{"go-w", 0777, 0755},
+		{"a=r", 0755, 0444},
+		{"+x", 0644, 0755},
+		{"u+rw,g-w,o=", 0775, 0750},
+	}
+
+	for _, tt := range tests {
+		got, err := parseFileMode(tt.mode, tt.current)
+		if err != nil {
+			t.Errorf("parseFileMode(%q): %v", tt.mode, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseFileMode(%q, %o) = %o, want %o", tt.mode, tt.current, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "0799", "1777", "u", "u+z", "q+x"} {
+		if _, err := parseFileMode(bad, 0); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestChmod_RecursiveSkipsSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix permissions")
+	}
+	base := t.TempDir()
+	outside := filepath.Join(base, "outside.txt")
+	tree := filepath.Join(base, "tree")
+	inside := filepath.Join(tree, "inside.txt")
+	if err := os.MkdirAll(tree, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{outside, inside} {
+		if err := os.WriteFile(p, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(tree, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Chmod(context.Background(), tree, "u+x", true); err != nil {
+		t.Fatalf("Chmod: %v", err)
+	}
+
+	if info, _ := os.Stat(inside); info.Mode().Perm() != 0o744 {
+		t.Errorf("inside mode = %o, want 744", info.Mode().Perm())
+	}
+	if info, _ := os.Stat(outside); info.Mode().Perm() != 0o644 {
+		t.Errorf("file outside the tree changed to %o through a symlink", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,323 @@
+package rclonelib
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DumpConfig returns rclone's configuration as a map of remote name to that
+// remote's parameters, using `rclone config dump`. Unlike reading the config
+// file directly this honours RCLONE_CONFIG, --config overrides and encrypted
+// config files.
+func DumpConfig(ctx context.Context) (map[string]map[string]string, error) {
+	output, err := runRclone(ctx, "config", "dump")
+	if err != nil {
+		return nil, fmt.Errorf("failed to dump config: %w", err)
+	}
+
+	config := make(map[string]map[string]string)
+	if err := json.Unmarshal(output, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config dump: %w", err)
+	}
+
+	return config, nil
+}
+
+// ConfigShow returns the parameters of a single remote
+func ConfigShow(ctx context.Context, remote string) (map[string]string, error) {
+	if remote == "" {
+		return nil, &ValidationError{Field: "remote", Message: "remote name cannot be empty"}
+	}
+
+	config, err := DumpConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	params, exists := config[strings.TrimSuffix(remote, ":")]
+	if !exists {
+		return nil, fmt.Errorf("remote not found in config: %s", remote)
+	}
+
+	return params, nil
+}
+
+// RemoteConfig is the configuration of a single remote
+type RemoteConfig struct {
+	Name string
+	Type string
+	// Params holds every other configured parameter, keyed by name
+	Params map[string]string
+}
+
+// ShowRemoteConfig returns the configuration of remote as a RemoteConfig
+func ShowRemoteConfig(ctx context.Context, remote string) (*RemoteConfig, error) {
+	params, err := ConfigShow(ctx, remote)
+	if err != nil {
+		return nil, err
+	}
+	return newRemoteConfig(strings.TrimSuffix(remote, ":"), params), nil
+}
+
+// ShowAllConfigs returns the configuration of every remote, sorted by name
+func ShowAllConfigs(ctx context.Context) ([]*RemoteConfig, error) {
+	config, err := DumpConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(config))
+	for name := range config {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	remotes := make([]*RemoteConfig, 0, len(names))
+	for _, name := range names {
+		remotes = append(remotes, newRemoteConfig(name, config[name]))
+	}
+	return remotes, nil
+}
+
+// newRemoteConfig splits the type out of a remote's dumped parameters
+func newRemoteConfig(name string, params map[string]string) *RemoteConfig {
+	rc := &RemoteConfig{Name: name, Params: make(map[string]string, len(params))}
+	for key, value := range params {
+		if key == "type" {
+			rc.Type = value
+			continue
+		}
+		rc.Params[key] = value
+	}
+	return rc
+}
+
+// UpdateRemoteConfig sets params on the existing remote name using `rclone
+// config update`. Parameters not mentioned are left as they are. It returns
+// ErrRemoteNotFound if the remote doesn't exist.
+func UpdateRemoteConfig(ctx context.Context, name string, params map[string]string) error {
+	name = strings.TrimSuffix(name, ":")
+	if name == "" {
+		return &ValidationError{Field: "name", Message: "remote name cannot be empty"}
+	}
+	if len(params) == 0 {
+		return &ValidationError{Field: "params", Message: "no parameters to update"}
+	}
+
+	remotes, err := ListRemotesFull(ctx)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, remote := range remotes {
+		if remote.Name == name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", ErrRemoteNotFound, name)
+	}
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	args := []string{"config", "update", name}
+	for _, key := range keys {
+		args = append(args, key, params[key])
+	}
+	args = append(args, "--non-interactive")
+
+	if _, err := runRclone(ctx, args...); err != nil {
+		return fmt.Errorf("failed to update remote %s: %w", name, err)
+	}
+	return nil
+}
+
+// UpdateRemoteConfigParam sets a single parameter on the existing remote name
+func UpdateRemoteConfigParam(ctx context.Context, name, key, value string) error {
+	if key == "" {
+		return &ValidationError{Field: "key", Message: "parameter name cannot be empty"}
+	}
+	return UpdateRemoteConfig(ctx, name, map[string]string{key: value})
+}
+
+// Provider describes a storage backend rclone supports, as reported by
+// `rclone config providers`
+type Provider struct {
+	Name        string
+	Description string
+	Prefix      string
+	Options     []ProviderOption
+	CommandHelp []CommandHelp
+}
+
+// ProviderOption is a configuration parameter of a backend
+type ProviderOption struct {
+	Name string
+	Help string
+	// Provider restricts the option to some sub-providers (e.g. "AWS,Ceph"
+	// for s3); empty means it always applies
+	Provider   string
+	Required   bool
+	IsPassword bool
+	Advanced   bool
+	Default    any
+}
+
+// CommandHelp describes a backend-specific command (`rclone backend`)
+type CommandHelp struct {
+	Name  string
+	Short string
+	Long  string
+	Opts  map[string]string
+}
+
+// ListProviders returns every backend rclone supports with its
+// configuration options, using `rclone config providers`
+func ListProviders(ctx context.Context) ([]Provider, error) {
+	output, err := runRclone(ctx, "config", "providers")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list providers: %w", err)
+	}
+
+	var providers []Provider
+	if err := json.Unmarshal(output, &providers); err != nil {
+		return nil, fmt.Errorf("failed to parse providers: %w", err)
+	}
+	return providers, nil
+}
+
+// ConfigFilePath returns the path of the config file rclone uses, from
+// `rclone config file`. The file may not exist yet; see ConfigExists.
+func ConfigFilePath(ctx context.Context) (string, error) {
+	output, err := runRclone(ctx, "config", "file")
+	if err != nil {
+		return "", fmt.Errorf("failed to get config file path: %w", err)
+	}
+	return parseConfigFilePath(string(output))
+}
+
+// parseConfigFilePath extracts the path from `rclone config file` output,
+// which is a description line followed by the path:
+//
+//	Configuration file is stored at:
+//	/home/user/.config/rclone/rclone.conf
+func parseConfigFilePath(output string) (string, error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	path := strings.TrimSpace(lines[len(lines)-1])
+	if len(lines) < 2 || path == "" {
+		return "", fmt.Errorf("unexpected rclone config file output: %q", output)
+	}
+	return path, nil
+}
+
+// ConfigExists reports whether rclone's config file exists
+func ConfigExists(ctx context.Context) (bool, error) {
+	path, err := ConfigFilePath(ctx)
+	if err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to stat config file: %w", err)
+	}
+	return true, nil
+}
+
+// ConfigFileDir returns the directory containing rclone's config file
+func ConfigFileDir(ctx context.Context) (string, error) {
+	path, err := ConfigFilePath(ctx)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(path), nil
+}
+
+// TouchConfig ensures rclone's config file exists, creating an empty one if
+// necessary, using `rclone config touch`
+func TouchConfig(ctx context.Context) error {
+	if _, err := runRclone(ctx, "config", "touch"); err != nil {
+		return fmt.Errorf("failed to touch config: %w", err)
+	}
+	return nil
+}
+
+// ObscurePassword returns plaintext in rclone's obscured form, as stored in
+// the config file for password parameters, using `rclone obscure`. The
+// plaintext is passed on stdin so it never appears in the process list.
+// Obscuring is reversible and is not encryption.
+func ObscurePassword(ctx context.Context, plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", &ValidationError{Field: "password", Message: "password cannot be empty"}
+	}
+
+	output, err := runRcloneInput(ctx, strings.NewReader(plaintext), nil, "obscure", "-")
+	if err != nil {
+		return "", fmt.Errorf("failed to obscure password: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// SetConfigPassword encrypts an unencrypted rclone config file with
+// newPassword using `rclone config encryption set` (rclone 1.65+). The
+// password is supplied on stdin so it never appears in the process list.
+func SetConfigPassword(ctx context.Context, newPassword string) error {
+	return setConfigEncryption(ctx, "", newPassword)
+}
+
+// ChangeConfigPassword re-encrypts an encrypted rclone config file, replacing
+// oldPassword with newPassword
+func ChangeConfigPassword(ctx context.Context, oldPassword, newPassword string) error {
+	if oldPassword == "" {
+		return &ValidationError{Field: "oldPassword", Message: "current password cannot be empty"}
+	}
+	return setConfigEncryption(ctx, oldPassword, newPassword)
+}
+
+// RemoveConfigPassword decrypts an encrypted rclone config file so it is
+// stored in plain text, using `rclone config encryption remove`
+func RemoveConfigPassword(ctx context.Context, currentPassword string) error {
+	if currentPassword == "" {
+		return &ValidationError{Field: "currentPassword", Message: "current password cannot be empty"}
+	}
+
+	env := []string{"RCLONE_CONFIG_PASS=" + currentPassword}
+	if _, err := runRcloneInput(ctx, nil, env, "config", "encryption", "remove", "--ask-password=false"); err != nil {
+		return fmt.Errorf("failed to remove config password: %w", err)
+	}
+	return nil
+}
+
+// setConfigEncryption runs `rclone config encryption set`, unlocking the
+// config with currentPassword (if any) and answering the new-password prompt
+// and its confirmation from stdin
+func setConfigEncryption(ctx context.Context, currentPassword, newPassword string) error {
+	if newPassword == "" {
+		return &ValidationError{Field: "newPassword", Message: "new password cannot be empty"}
+	}
+	if strings.ContainsAny(newPassword, "\r\n") {
+		return &ValidationError{Field: "newPassword", Message: "password cannot contain line breaks"}
+	}
+
+	var env []string
+	if currentPassword != "" {
+		env = append(env, "RCLONE_CONFIG_PASS="+currentPassword)
+	}
+
+	stdin := strings.NewReader(newPassword + "\n" + newPassword + "\n")
+	if _, err := runRcloneInput(ctx, stdin, env, "config", "encryption", "set", "--ask-password=false"); err != nil {
+		return fmt.Errorf("failed to set config password: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package rclonelib
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ConfigOption is a single remote parameter added to a ConfigBuilder. Value
+// is used as is unless Env is set, in which case the value is read from that
+// environment variable when the config is built.
+type ConfigOption struct {
+	Key   string
+	Value string
+	Env   string
+}
+
+// ConfigBuilder assembles the parameters of a remote, e.g.
+//
+//	err := NewConfigBuilder().
+//		Set("provider", "AWS").
+//		SetEnvVar("access_key_id", "AWS_ACCESS_KEY_ID").
+//		SetEnvVar("secret_access_key", "AWS_SECRET_ACCESS_KEY").
+//		WriteToConfigFile(ctx, "mys3", "s3", "")
+type ConfigBuilder struct {
+	options []ConfigOption
+	errs    []error
+}
+
+// NewConfigBuilder returns an empty ConfigBuilder
+func NewConfigBuilder() *ConfigBuilder {
+	return &ConfigBuilder{}
+}
+
+// Set sets key to value
+func (b *ConfigBuilder) Set(key, value string) *ConfigBuilder {
+	return b.add(ConfigOption{Key: key, Value: value})
+}
+
+// SetEnvVar sets key to the value of the environment variable envVarName,
+// read when the config is built
+func (b *ConfigBuilder) SetEnvVar(key, envVarName string) *ConfigBuilder {
+	if envVarName == "" {
+		b.errs = append(b.errs, &ValidationError{Field: key, Message: "environment variable name cannot be empty"})
+		return b
+	}
+	return b.add(ConfigOption{Key: key, Env: envVarName})
+}
+
+// SetObscured sets key to plaintext obscured with ObscurePassword, as rclone
+// expects for password parameters
+func (b *ConfigBuilder) SetObscured(key, plaintext string, ctx context.Context) *ConfigBuilder {
+	obscured, err := ObscurePassword(ctx, plaintext)
+	if err != nil {
+		b.errs = append(b.errs, fmt.Errorf("%s: %w", key, err))
+		return b
+	}
+	return b.add(ConfigOption{Key: key, Value: obscured})
+}
+
+// add records opt, replacing any earlier option with the same key
+func (b *ConfigBuilder) add(opt ConfigOption) *ConfigBuilder {
+	if opt.Key == "" {
+		b.errs = append(b.errs, &ValidationError{Field: "key", Message: "parameter name cannot be empty"})
+		return b
+	}
+	for i := range b.options {
+		if b.options[i].Key == opt.Key {
+			b.options[i] = opt
+			return b
+		}
+	}
+	b.options = append(b.options, opt)
+	return b
+}
+
+// Build returns the parameters as a map. Options whose environment variable
+// is unset are left out; use Err to find out about them.
+func (b *ConfigBuilder) Build() map[string]string {
+	params, _ := b.build()
+	return params
+}
+
+// Err returns the first problem found while adding options or reading
+// environment variables, or nil
+func (b *ConfigBuilder) Err() error {
+	_, err := b.build()
+	return err
+}
+
+func (b *ConfigBuilder) build() (map[string]string, error) {
+	var firstErr error
+	if len(b.errs) > 0 {
+		firstErr = b.errs[0]
+	}
+
+	params := make(map[string]string, len(b.options))
+	for _, opt := range b.options {
+		if opt.Env == "" {
+			params[opt.Key] = opt.Value
+			continue
+		}
+		value, ok := os.LookupEnv(opt.Env)
+		if !ok {
+			if firstErr == nil {
+				firstErr = &ValidationError{Field: opt.Key, Message: fmt.Sprintf("environment variable %s is not set", opt.Env)}
+			}
+			continue
+		}
+		params[opt.Key] = value
+	}
+	return params, firstErr
+}
+
+// WriteToConfigFile creates the remote remoteName of type remoteType with the
+// built parameters using `rclone config create`. configPath selects the
+// config file; empty means rclone's default.
+//
+// The parameters are passed to rclone as arguments, since `rclone config
+// create` only saves what is on its command line. While it runs they are
+// visible in the process list to other users of the machine, secrets
+// included; where that matters, create the remote on a trusted host or
+// write the config file yourself.
+func (b *ConfigBuilder) WriteToConfigFile(ctx context.Context, remoteName, remoteType, configPath string) error {
+	remoteName = strings.TrimSuffix(remoteName, ":")
+	if remoteName == "" {
+		return &ValidationError{Field: "name", Message: "remote name cannot be empty"}
+	}
+	if remoteType == "" {
+		return &ValidationError{Field: "type", Message: "remote type cannot be empty"}
+	}
+
+	params, err := b.build()
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	args := []string{"config", "create", remoteName, remoteType}
+	for _, key := range keys {
+		args = append(args, key, params[key])
+	}
+	args = append(args, "--non-interactive")
+	if configPath != "" {
+		args = append(args, "--config", configPath)
+	}
+
+	if _, err := runRclone(ctx, args...); err != nil {
+		return fmt.Errorf("failed to create remote %s: %w", remoteName, err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package rclonelib
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeRclone puts a shell script named rclone first on PATH for the rest of
+// the test
+func fakeRclone(t *testing.T, script string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake rclone is a shell script")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "rclone"), []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// fakeConfigCreate mimics `rclone config create NAME TYPE [key value]...
+// --non-interactive --config FILE`, saving only what is on the command line
+const fakeConfigCreate = `[ "$1 $2" = "config create" ] || exit 1
+name=$3; type=$4; shift 4
+out=""; body=""
+while [ $# -gt 0 ]; do
+	case "$1" in
+	--non-interactive) shift ;;
+	--config) out=$2; shift 2 ;;
+	*) body="$body$1 = $2
+"; shift 2 ;;
+	esac
+done
+printf '[%s]\ntype = %s\n%s' "$name" "$type" "$body" >> "$out"
+`
+
+func TestConfigBuilder_WriteToConfigFile(t *testing.T) {
+	fakeRclone(t, fakeConfigCreate)
+	t.Setenv("TEST_SECRET_KEY", "s3cr3t")
+	configPath := filepath.Join(t.TempDir(), "rclone.conf")
+
+	err := NewConfigBuilder().
+		Set("provider", "AWS").
+		Set("access_key_id", "AKIA123").
+		SetEnvVar("secret_access_key", "TEST_SECRET_KEY").
+		WriteToConfigFile(context.Background(), "mys3:", "s3", configPath)
+	if err != nil {
+		t.Fatalf("WriteToConfigFile: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"[mys3]", "type = s3", "provider = AWS", "access_key_id = AKIA123", "secret_access_key = s3cr3t"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("config missing %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestConfigBuilder_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	if err := NewConfigBuilder().WriteToConfigFile(ctx, "", "s3", ""); err == nil {
+		t.Error("expected error for empty remote name")
+	}
+	if err := NewConfigBuilder().WriteToConfigFile(ctx, "r", "", ""); err == nil {
+		t.Error("expected error for empty remote type")
+	}
+
+	os.Unsetenv("RCLONELIB_TEST_UNSET")
+	b := NewConfigBuilder().Set("a", "1").SetEnvVar("b", "RCLONELIB_TEST_UNSET")
+	if err := b.Err(); err == nil {
+		t.Error("expected error for unset environment variable")
+	}
+	if params := b.Build(); params["a"] != "1" || len(params) != 1 {
+		t.Errorf("Build = %v", params)
+	}
+	if err := b.WriteToConfigFile(ctx, "r", "s3", ""); err == nil {
+		t.Error("WriteToConfigFile should refuse an incomplete config")
+	}
+}
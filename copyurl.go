@@ -0,0 +1,136 @@
+package rclonelib
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// CopyURLOptions configures CopyURL
+type CopyURLOptions struct {
+	// AutoFilename treats the destination as a directory and names the file
+	// after the last segment of the URL
+	AutoFilename bool
+	// UseContentDisposition names the file from the server's
+	// Content-Disposition header when it sends one, falling back to the URL.
+	// Implies AutoFilename.
+	UseContentDisposition bool
+	// FollowRedirects resolves redirects before handing the URL to rclone, so
+	// an auto-generated filename comes from the final URL rather than the
+	// redirecting one
+	FollowRedirects bool
+	// NoClobber refuses to overwrite an existing destination file
+	NoClobber bool
+}
+
+// URLInfo describes a URL as reported by a HEAD request
+type URLInfo struct {
+	// URL is the final URL after any redirects
+	URL           string
+	ContentLength int64 // -1 if the server didn't say
+	ContentType   string
+	// Filename comes from Content-Disposition if present, otherwise the last
+	// segment of the URL path
+	Filename string
+}
+
+// CopyURL downloads url straight to dst using `rclone copyurl`, without
+// staging it locally. It returns the path that was written, which with
+// AutoFilename or UseContentDisposition set is the chosen filename inside
+// dst.
+func CopyURL(ctx context.Context, url, dst string, opts CopyURLOptions) (string, error) {
+	if url == "" {
+		return "", &ValidationError{Field: "url", Message: "URL cannot be empty"}
+	}
+	if dst == "" {
+		return "", &ValidationError{Field: "destination", Message: "destination cannot be empty"}
+	}
+
+	if opts.FollowRedirects {
+		info, err := GetURLInfo(ctx, url)
+		if err != nil {
+			return "", err
+		}
+		url = info.URL
+	}
+
+	auto := opts.AutoFilename || opts.UseContentDisposition
+	args := []string{"copyurl", url, dst}
+	if auto {
+		args = append(args, "--auto-filename", "--print-filename")
+	}
+	if opts.UseContentDisposition {
+		args = append(args, "--header-filename")
+	}
+	if opts.NoClobber {
+		args = append(args, "--no-clobber")
+	}
+
+	output, err := runRclone(ctx, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to copy %s to %s: %w", url, dst, err)
+	}
+
+	if !auto {
+		return dst, nil
+	}
+	name := strings.TrimSpace(string(output))
+	if name == "" {
+		return "", fmt.Errorf("rclone copyurl did not report a filename for %s", url)
+	}
+	return childPath(dst, name), nil
+}
+
+// GetURLInfo issues a HEAD request for url, following redirects, and reports
+// its size, type and filename without downloading the body. Useful for
+// checking free space before a CopyURL.
+func GetURLInfo(ctx context.Context, url string) (*URLInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return nil, &ValidationError{Field: "url", Message: err.Error()}
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch URL info: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("failed to fetch URL info: %s", resp.Status)
+	}
+
+	info := &URLInfo{
+		URL:           resp.Request.URL.String(),
+		ContentLength: resp.ContentLength,
+		ContentType:   resp.Header.Get("Content-Type"),
+		Filename:      contentDispositionFilename(resp.Header.Get("Content-Disposition")),
+	}
+	if info.Filename == "" {
+		if base := path.Base(resp.Request.URL.Path); base != "/" && base != "." {
+			info.Filename = base
+		}
+	}
+
+	return info, nil
+}
+
+// contentDispositionFilename extracts the filename parameter from a
+// Content-Disposition header, ignoring any directory components
+func contentDispositionFilename(header string) string {
+	if header == "" {
+		return ""
+	}
+	_, params, err := mime.ParseMediaType(header)
+	if err != nil {
+		return ""
+	}
+	name := params["filename"]
+	if name == "" {
+		return ""
+	}
+	return path.Base(strings.ReplaceAll(name, "\\", "/"))
+}
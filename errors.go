@@ -24,10 +24,104 @@ const (
 	ErrorTypeInvalidInput ErrorType = "invalid_input"
 	// ErrorTypeInsufficientSpace represents insufficient disk space errors
 	ErrorTypeInsufficientSpace ErrorType = "insufficient_space"
+	// ErrorTypeVerificationFailed represents source and destination found to
+	// differ by Executor.Verify
+	ErrorTypeVerificationFailed ErrorType = "verification_failed"
 	// ErrorTypeUnknown represents unknown errors
 	ErrorTypeUnknown ErrorType = "unknown"
 )
 
+// Sentinel errors returned by the library
+var (
+	// ErrManagerDraining is returned by Manager.Start after Manager.Drain has been called
+	ErrManagerDraining = errors.New("manager is draining")
+	// ErrDestructiveOperation is returned when a delete or purge would remove
+	// the root of a remote or filesystem
+	ErrDestructiveOperation = errors.New("refusing destructive operation")
+	// ErrValidationTimeout is returned when a local path check doesn't finish
+	// in time, typically because a network mount has stopped responding
+	ErrValidationTimeout = errors.New("validation timed out")
+	// ErrLinkUnsupported is returned by CreateLink when the remote's backend
+	// can't create public links
+	ErrLinkUnsupported = errors.New("remote does not support public links")
+	// ErrTransferTimeout is returned by ExecuteWithTimeout when the transfer
+	// runs past its deadline
+	ErrTransferTimeout = errors.New("transfer timed out")
+	// ErrRemoteNotFound is returned when a named remote isn't in rclone's config
+	ErrRemoteNotFound = errors.New("remote not found")
+	// ErrTransferNotFound is returned when a transfer ID isn't known to the
+	// manager
+	ErrTransferNotFound = errors.New("transfer not found")
+	// ErrTransferInProgress is returned by Manager.Reset for a transfer that
+	// is still running
+	ErrTransferInProgress = errors.New("transfer is in progress")
+	// ErrNotFound is returned when a file or directory doesn't exist
+	ErrNotFound = errors.New("not found")
+	// ErrRemoteQuotaUnsupported is returned when a remote's backend can't
+	// report its free space. It means the check couldn't be made, not that
+	// space is short, so callers may treat it as a warning.
+	ErrRemoteQuotaUnsupported = errors.New("remote does not report quota")
+	// ErrChmodUnsupported is returned by Chmod and Chown for paths whose
+	// permissions can't be changed, which is currently any remote path
+	ErrChmodUnsupported = errors.New("changing permissions is not supported")
+	// ErrFeatureNotSupported is returned by RequireFeature when the installed
+	// rclone is too old for a feature
+	ErrFeatureNotSupported = errors.New("feature not supported by this rclone version")
+	// ErrPoolClosed is returned by Pool.Submit once Pool.Wait has been called
+	ErrPoolClosed = errors.New("pool is closed")
+)
+
+// ErrNoSpaceLeft reports that a destination lacks room for a transfer. It is
+// returned by CheckDiskSpace with the sizes filled in, and by Executor.Execute
+// (wrapping rclone's error) when rclone reports a full disk or exhausted
+// quota, in which case the sizes are unknown and left at zero.
+type ErrNoSpaceLeft struct {
+	Path      string
+	Required  int64
+	Available int64
+	// Err is the underlying error, if any
+	Err error
+}
+
+func (e *ErrNoSpaceLeft) Error() string {
+	if e.Required > 0 {
+		return fmt.Sprintf("insufficient disk space: need %s, have %s",
+			FormattedBytes(e.Required), FormattedBytes(e.Available))
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("no space left on %s: %v", e.Path, e.Err)
+	}
+	return fmt.Sprintf("no space left on %s", e.Path)
+}
+
+func (e *ErrNoSpaceLeft) Unwrap() error {
+	return e.Err
+}
+
+// FormattedMessage returns a user-facing description including the path and
+// how much space is missing
+func (e *ErrNoSpaceLeft) FormattedMessage() string {
+	if e.Required > 0 {
+		return fmt.Sprintf("Not enough space on %s: %s required, %s available (%s short)",
+			e.Path, FormattedBytes(e.Required), FormattedBytes(e.Available),
+			FormattedBytes(e.Required-e.Available))
+	}
+	return fmt.Sprintf("Not enough space on %s", e.Path)
+}
+
+// isNoSpaceMessage reports whether an error message describes a full disk or
+// exhausted storage quota. A bare "quota exceeded" isn't enough: Google Drive
+// uses it for API rate limits ("Quota exceeded for quota metric ...,
+// rateLimitExceeded"), which are worth retrying.
+func isNoSpaceMessage(msg string) bool {
+	msg = strings.ToLower(msg)
+	return strings.Contains(msg, "no space left") ||
+		strings.Contains(msg, "insufficient space") ||
+		strings.Contains(msg, "disk full") ||
+		strings.Contains(msg, "storagequotaexceeded") ||
+		strings.Contains(msg, "storage quota")
+}
+
 // ClassifiedError wraps an error with classification information
 type ClassifiedError struct {
 	Type      ErrorType
@@ -52,7 +146,22 @@ func ClassifyError(err error) *ClassifiedError {
 
 	errStr := strings.ToLower(err.Error())
 
-	// Check for validation errors first
+	// Typed errors first: they carry their own classification
+	var spaceErr *ErrNoSpaceLeft
+	if errors.As(err, &spaceErr) {
+		return &ClassifiedError{
+			Type:      ErrorTypeInsufficientSpace,
+			Err:       err,
+			Retryable: false,
+			Temporary: false,
+		}
+	}
+
+	var classified *ClassifiedError
+	if errors.As(err, &classified) && classified.Type == ErrorTypeVerificationFailed {
+		return classified
+	}
+
 	var valErr *ValidationError
 	if errors.As(err, &valErr) {
 		return &ClassifiedError{
@@ -117,10 +226,7 @@ func ClassifyError(err error) *ClassifiedError {
 	}
 
 	// Disk space errors
-	if strings.Contains(errStr, "no space left") ||
-		strings.Contains(errStr, "insufficient space") ||
-		strings.Contains(errStr, "disk full") ||
-		strings.Contains(errStr, "quota exceeded") {
+	if isNoSpaceMessage(errStr) {
 		return &ClassifiedError{
 			Type:      ErrorTypeInsufficientSpace,
 			Err:       err,
@@ -0,0 +1,271 @@
+package rclonelib
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// eventBufferSize is the capacity of subscriber channels. Events are dropped
+// for subscribers that fall this far behind rather than stalling rclone's
+// output parsing.
+const eventBufferSize = 64
+
+// TransferEvent describes something notable that happened during a transfer
+// beyond its status and progress. Exactly one of the event fields is set.
+type TransferEvent struct {
+	TransferID string
+	Time       time.Time
+
+	// RateLimit is set when rclone reported that the remote throttled it
+	RateLimit *RateLimitEvent
+	// Log is set for each log line rclone wrote
+	Log *LogEvent
+	// Resume is set when ResumableTransfer picks up an interrupted transfer
+	Resume *ResumeEvent
+	// SourceChanged is set when a source watched with WatchSource changes
+	// while the transfer is running
+	SourceChanged *SourceChangedEvent
+}
+
+// SourceChangedEvent records a change to a transfer's source made while it
+// was running
+type SourceChangedEvent struct {
+	FileChangeEvent
+}
+
+// LogEvent is a single line of rclone's log output
+type LogEvent struct {
+	TransferID string
+	// Level is rclone's log level, e.g. "INFO", "NOTICE" or "ERROR"
+	Level   string
+	Message string
+	Time    time.Time
+}
+
+// RateLimitEvent records that a remote throttled a transfer
+type RateLimitEvent struct {
+	// Remote is the remote name of the transfer's destination, or of its
+	// source when the destination is local
+	Remote string
+	// RetryAfter is how long rclone was told to wait, or 0 if it didn't say
+	RetryAfter time.Duration
+}
+
+// Subscribe returns a channel that receives every TransferEvent emitted by
+// the manager from now on. The channel is buffered; events are dropped if the
+// subscriber doesn't keep up. Call Unsubscribe when done.
+func (m *Manager) Subscribe() <-chan TransferEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ch := make(chan TransferEvent, eventBufferSize)
+	m.subscribers = append(m.subscribers, ch)
+	return ch
+}
+
+// Unsubscribe stops delivery to a channel returned by Subscribe and closes it
+func (m *Manager) Unsubscribe(ch <-chan TransferEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, sub := range m.subscribers {
+		if sub == ch {
+			m.subscribers = append(m.subscribers[:i], m.subscribers[i+1:]...)
+			close(sub)
+			return
+		}
+	}
+}
+
+// LogSubscribe returns a channel that receives only rclone's log lines, for
+// callers that want a log feed without the rest of the events. Like
+// Subscribe it is buffered and drops lines if the subscriber falls behind.
+// Call LogUnsubscribe when done.
+func (m *Manager) LogSubscribe() <-chan LogEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ch := make(chan LogEvent, eventBufferSize)
+	m.logSubscribers = append(m.logSubscribers, ch)
+	return ch
+}
+
+// LogUnsubscribe stops delivery to a channel returned by LogSubscribe and
+// closes it
+func (m *Manager) LogUnsubscribe(ch <-chan LogEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, sub := range m.logSubscribers {
+		if sub == ch {
+			m.logSubscribers = append(m.logSubscribers[:i], m.logSubscribers[i+1:]...)
+			close(sub)
+			return
+		}
+	}
+}
+
+// emitLocked delivers ev to all subscribers without blocking. The caller must
+// hold the lock.
+func (m *Manager) emitLocked(ev TransferEvent) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	for _, sub := range m.subscribers {
+		select {
+		case sub <- ev:
+		default:
+		}
+	}
+}
+
+// recordRateLimit stores a rate-limit event on the transfer and emits it
+func (m *Manager) recordRateLimit(id string, retryAfter time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, exists := m.transfers[id]
+	if !exists {
+		return
+	}
+
+	path := t.Destination
+	if !IsRemotePath(path) {
+		path = t.Source
+	}
+	remote := ""
+	if IsRemotePath(path) {
+		remote, _ = SplitRemotePath(path)
+	}
+
+	ev := &RateLimitEvent{Remote: remote, RetryAfter: retryAfter}
+	t.RateLimit = ev
+	m.emitLocked(TransferEvent{TransferID: id, RateLimit: ev})
+}
+
+// recordLog emits a log line from a transfer to event and log subscribers
+func (m *Manager) recordLog(id string, ev LogEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.subscribers) == 0 && len(m.logSubscribers) == 0 {
+		return
+	}
+
+	ev.TransferID = id
+	m.emitLocked(TransferEvent{TransferID: id, Time: ev.Time, Log: &ev})
+	for _, sub := range m.logSubscribers {
+		select {
+		case sub <- ev:
+		default:
+		}
+	}
+}
+
+// recordSourceChanged emits a source change for a running transfer
+func (m *Manager) recordSourceChanged(id string, change FileChangeEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, exists := m.transfers[id]
+	if !exists || !t.IsActive() {
+		return
+	}
+	m.emitLocked(TransferEvent{TransferID: id, SourceChanged: &SourceChangedEvent{change}})
+}
+
+// recordResume emits that a transfer is resuming an earlier attempt
+func (m *Manager) recordResume(id string, ev *ResumeEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.emitLocked(TransferEvent{TransferID: id, Resume: ev})
+}
+
+// takeRateLimit returns and clears the rate-limit event stored on a transfer
+func (m *Manager) takeRateLimit(id string) *RateLimitEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, exists := m.transfers[id]
+	if !exists {
+		return nil
+	}
+	ev := t.RateLimit
+	t.RateLimit = nil
+	return ev
+}
+
+var (
+	// rateLimitMarkers are fragments of rclone's (and common backends')
+	// throttling messages, matched case-insensitively
+	rateLimitMarkers = []string{
+		"too many requests",
+		"rate: wait",
+		"rate limit exceeded",
+		"ratelimitexceeded",
+	}
+
+	// logLineRegex matches rclone's log format:
+	// "2024/01/02 15:04:05 INFO  : file.txt: Copied (new)"
+	logLineRegex = regexp.MustCompile(`^(\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2})(?:\.\d+)?\s+([A-Z]+)\s*:\s?(.*)$`)
+
+	// retryAfterRegex picks the wait time out of a throttling message, either
+	// as a Go duration ("1m30s") or a number of seconds ("in 12 seconds")
+	retryAfterRegex = regexp.MustCompile(`\b((?:\d+(?:\.\d+)?(?:ms|s|m|h))+)\b|\b(\d+(?:\.\d+)?)\s*seconds?\b`)
+)
+
+// parseLogLine parses a line of rclone's log output. The timestamp is read
+// in local time, which is how rclone writes it.
+func parseLogLine(line string) (LogEvent, bool) {
+	matches := logLineRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return LogEvent{}, false
+	}
+
+	ts, err := time.ParseInLocation("2006/01/02 15:04:05", matches[1], time.Local)
+	if err != nil {
+		return LogEvent{}, false
+	}
+	return LogEvent{Level: matches[2], Message: strings.TrimSpace(matches[3]), Time: ts}, true
+}
+
+// parseRateLimit reports whether line is a rate-limit message and, if rclone
+// said how long to wait, for how long
+func parseRateLimit(line string) (time.Duration, bool) {
+	lower := strings.ToLower(line)
+
+	found := false
+	for _, marker := range rateLimitMarkers {
+		if i := strings.Index(lower, marker); i >= 0 {
+			found = true
+			// Only look for a wait time after the marker so timestamps and
+			// file names earlier in the line aren't mistaken for one.
+			lower = lower[i+len(marker):]
+			break
+		}
+	}
+	if !found {
+		return 0, false
+	}
+
+	matches := retryAfterRegex.FindStringSubmatch(lower)
+	switch {
+	case matches == nil:
+		return 0, true
+	case matches[1] != "":
+		d, err := time.ParseDuration(matches[1])
+		if err != nil {
+			return 0, true
+		}
+		return d, true
+	default:
+		secs, err := strconv.ParseFloat(matches[2], 64)
+		if err != nil {
+			return 0, true
+		}
+		return time.Duration(secs * float64(time.Second)), true
+	}
+}
@@ -34,9 +34,9 @@ func main() {
 		fmt.Println("Example: advanced-example /path/to/file remote:path/to/destination")
 		fmt.Println()
 		fmt.Println("Available remotes:")
-		remotes, _ := rclone.ListRemotes(ctx)
+		remotes, _ := rclone.ListRemotesFull(ctx)
 		for _, remote := range remotes {
-			fmt.Printf("  - %s\n", remote)
+			fmt.Printf("  - %s (%s)\n", remote.Name, remote.Type)
 		}
 		os.Exit(1)
 	}
@@ -0,0 +1,71 @@
+package rclonelib
+
+import (
+	"errors"
+	"os/exec"
+	"slices"
+)
+
+// rclone exit codes, see https://rclone.org/docs/#exit-code
+const (
+	ExitCodeSuccess            = 0
+	ExitCodeSyntaxError        = 1
+	ExitCodeUncategorized      = 2
+	ExitCodeDirNotFound        = 3
+	ExitCodeFileNotFound       = 4
+	ExitCodeTemporaryError     = 5
+	ExitCodeLessSerious        = 6
+	ExitCodeFatalError         = 7
+	ExitCodeTransferExceeded   = 8
+	ExitCodeNoFilesTransferred = 9
+)
+
+// ExitCodePolicy tells Execute how to interpret rclone's exit code. Codes
+// not listed are errors, classified as usual. The zero policy (or a nil
+// *ExitCodePolicy) treats every non-zero exit as an error.
+type ExitCodePolicy struct {
+	// TreatAsSuccess lists exit codes for which Execute returns nil, e.g.
+	// ExitCodeNoFilesTransferred when nothing needing transfer is fine
+	TreatAsSuccess []int
+	// TreatAsRetryable lists exit codes ExecuteWithRetry should retry
+	TreatAsRetryable []int
+	// TreatAsFatal lists exit codes ExecuteWithRetry should not retry
+	TreatAsFatal []int
+}
+
+// apply interprets err from running rclone according to the policy. Errors
+// for retryable or fatal codes are returned as a *ClassifiedError with
+// Retryable set accordingly; it unwraps to the original error.
+func (p *ExitCodePolicy) apply(err error) error {
+	if p == nil || err == nil {
+		return err
+	}
+
+	code, ok := exitCode(err)
+	if !ok {
+		return err
+	}
+
+	switch {
+	case slices.Contains(p.TreatAsSuccess, code):
+		return nil
+	case slices.Contains(p.TreatAsFatal, code):
+		classified := ClassifyError(err)
+		classified.Retryable = false
+		return classified
+	case slices.Contains(p.TreatAsRetryable, code):
+		classified := ClassifyError(err)
+		classified.Retryable = true
+		return classified
+	}
+	return err
+}
+
+// exitCode extracts rclone's exit code from an error returned by exec
+func exitCode(err error) (int, bool) {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), true
+	}
+	return 0, false
+}
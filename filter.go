@@ -0,0 +1,123 @@
+package rclonelib
+
+import "strings"
+
+// Filter returns the transfers matching pred in insertion order. The
+// predicate is evaluated under the manager's read lock, so it must not call
+// back into the Manager.
+func (m *Manager) Filter(pred func(*Transfer) bool) []*Transfer {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*Transfer
+	for _, id := range m.order {
+		if t, exists := m.transfers[id]; exists && pred(t) {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// Partition splits the transfers into those matching pred and the rest,
+// both in insertion order. As with Filter, pred runs under the read lock.
+func (m *Manager) Partition(pred func(*Transfer) bool) (matching, rest []*Transfer) {
+	matching = m.Filter(func(t *Transfer) bool {
+		if pred(t) {
+			return true
+		}
+		rest = append(rest, t)
+		return false
+	})
+	return matching, rest
+}
+
+// GroupBy groups the transfers by the string key returns for each, e.g. by
+// destination remote. Each group is in insertion order. As with Filter, key
+// runs under the read lock.
+func (m *Manager) GroupBy(key func(*Transfer) string) map[string][]*Transfer {
+	groups := make(map[string][]*Transfer)
+	m.Filter(func(t *Transfer) bool {
+		k := key(t)
+		groups[k] = append(groups[k], t)
+		return false
+	})
+	return groups
+}
+
+// GetBySourcePrefix returns the transfers whose source starts with prefix,
+// e.g. everything copied out of one bucket or project directory
+func (m *Manager) GetBySourcePrefix(prefix string) []*Transfer {
+	return m.Filter(BySourcePrefix(prefix))
+}
+
+// GetByDestinationPrefix returns the transfers whose destination starts with
+// prefix
+func (m *Manager) GetByDestinationPrefix(prefix string) []*Transfer {
+	return m.Filter(ByDestinationPrefix(prefix))
+}
+
+// Count returns the number of transfers matching pred
+func (m *Manager) Count(pred func(*Transfer) bool) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	count := 0
+	for _, t := range m.transfers {
+		if pred(t) {
+			count++
+		}
+	}
+	return count
+}
+
+// ByStatus matches transfers with the given status
+func ByStatus(s Status) func(*Transfer) bool {
+	return func(t *Transfer) bool {
+		return t.Status == s
+	}
+}
+
+// BySource matches transfers whose source contains substr
+func BySource(substr string) func(*Transfer) bool {
+	return func(t *Transfer) bool {
+		return strings.Contains(t.Source, substr)
+	}
+}
+
+// ByDestination matches transfers whose destination contains substr
+func ByDestination(substr string) func(*Transfer) bool {
+	return func(t *Transfer) bool {
+		return strings.Contains(t.Destination, substr)
+	}
+}
+
+// BySourcePrefix matches transfers whose source starts with prefix
+func BySourcePrefix(prefix string) func(*Transfer) bool {
+	return func(t *Transfer) bool {
+		return strings.HasPrefix(t.Source, prefix)
+	}
+}
+
+// ByDestinationPrefix matches transfers whose destination starts with prefix
+func ByDestinationPrefix(prefix string) func(*Transfer) bool {
+	return func(t *Transfer) bool {
+		return strings.HasPrefix(t.Destination, prefix)
+	}
+}
+
+// Completed matches transfers that finished successfully
+func Completed() func(*Transfer) bool {
+	return ByStatus(StatusCompleted)
+}
+
+// Failed matches transfers that failed
+func Failed() func(*Transfer) bool {
+	return ByStatus(StatusFailed)
+}
+
+// Active matches transfers that are currently running
+func Active() func(*Transfer) bool {
+	return func(t *Transfer) bool {
+		return t.IsActive()
+	}
+}
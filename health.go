@@ -0,0 +1,95 @@
+package rclonelib
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// defaultFailureThreshold is how many failures since the last reset a Manager
+// tolerates before HealthCheck reports it as unhealthy
+const defaultFailureThreshold = 5
+
+// HealthStatus is a point-in-time summary of a Manager for readiness probes
+type HealthStatus struct {
+	Healthy          bool   `json:"healthy"`
+	InProgress       int    `json:"in_progress"`
+	Pending          int    `json:"pending"`
+	FailedSinceReset int    `json:"failed_since_reset"`
+	Message          string `json:"message"`
+}
+
+// WithFailureThreshold sets how many failures since the last
+// ResetFailureCount make HealthCheck report the manager as unhealthy
+// (default: 5). Values below 1 are ignored.
+func WithFailureThreshold(n int) ManagerOption {
+	return func(m *Manager) {
+		if n > 0 {
+			m.failureThreshold = n
+		}
+	}
+}
+
+// HealthCheck reports whether the manager is fit to accept work. It is
+// unhealthy while draining or once the number of failures since the last
+// ResetFailureCount reaches the configured threshold.
+func (m *Manager) HealthCheck() HealthStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	status := HealthStatus{
+		Healthy:          true,
+		FailedSinceReset: m.failedSinceReset,
+		Message:          "ok",
+	}
+	for _, t := range m.transfers {
+		switch t.Status {
+		case StatusPending:
+			status.Pending++
+		case StatusInProgress:
+			status.InProgress++
+		}
+	}
+
+	switch {
+	case m.draining:
+		status.Healthy = false
+		status.Message = "draining"
+	case m.failedSinceReset >= m.failureThreshold:
+		status.Healthy = false
+		status.Message = fmt.Sprintf("%d failures since last reset (threshold %d)",
+			m.failedSinceReset, m.failureThreshold)
+	}
+
+	return status
+}
+
+// ResetFailureCount clears the failure counter used by HealthCheck
+func (m *Manager) ResetFailureCount() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failedSinceReset = 0
+}
+
+// ServeHealth starts an HTTP server on addr that answers requests to path
+// with the manager's HealthStatus as JSON. Unhealthy managers respond with
+// 503 so the endpoint can be used directly as a readiness probe. It blocks
+// like http.ListenAndServe.
+func ServeHealth(manager *Manager, addr, path string) error {
+	if path == "" {
+		path = "/"
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		status := manager.HealthCheck()
+
+		w.Header().Set("Content-Type", "application/json")
+		if !status.Healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(status)
+	})
+
+	return http.ListenAndServe(addr, mux)
+}
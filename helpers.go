@@ -1,12 +1,79 @@
 package rclonelib
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// runRclone runs an rclone subcommand and returns its stdout. When rclone
+// exits non-zero its stderr is folded into the error, so callers (and
+// ClassifyError) can see why rather than a bare exit status.
+func runRclone(ctx context.Context, args ...string) ([]byte, error) {
+	return runRcloneInput(ctx, nil, nil, args...)
+}
+
+// runRcloneCombined runs an rclone subcommand and returns stdout and stderr
+// interleaved, for commands that report their results through the log
+func runRcloneCombined(ctx context.Context, args ...string) ([]byte, error) {
+	return runRcloneCombinedEnv(ctx, nil, args...)
+}
+
+// runRcloneCombinedEnv is runRcloneCombined with rclone's whole environment
+// replaced by env, as built by buildEnv, when it is non-nil
+func runRcloneCombinedEnv(ctx context.Context, env []string, args ...string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, "rclone", args...)
+	cmd.Env = env
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return output, fmt.Errorf("%w: %s", err, strings.Join(strings.Fields(string(output)), " "))
+	}
+	return output, nil
+}
+
+// runRcloneInput is runRclone with stdin connected to the given reader and
+// extra environment variables ("KEY=value") added to the inherited ones
+func runRcloneInput(ctx context.Context, stdin io.Reader, env []string, args ...string) ([]byte, error) {
+	// Fail fast on a cancelled context rather than relying on exec, which
+	// reports a missing binary ahead of cancellation.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, "rclone", args...)
+	cmd.Stdin = stdin
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return output, fmt.Errorf("%w: %s", err, strings.Join(strings.Fields(msg), " "))
+		}
+		return output, err
+	}
+	return output, nil
+}
+
 // ListFiles lists files in a remote or local path
 func ListFiles(ctx context.Context, path string, recursive bool) ([]string, error) {
 	args := []string{"lsf", path}
@@ -14,8 +81,7 @@ func ListFiles(ctx context.Context, path string, recursive bool) ([]string, erro
 		args = append(args, "--max-depth", "1")
 	}
 
-	cmd := exec.CommandContext(ctx, "rclone", args...)
-	output, err := cmd.Output()
+	output, err := runRclone(ctx, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list files: %w", err)
 	}
@@ -31,10 +97,313 @@ func ListFiles(ctx context.Context, path string, recursive bool) ([]string, erro
 	return files, nil
 }
 
+// errStopListing is returned by a ListFilesPaged callback to end the listing
+// early without error
+var errStopListing = errors.New("stop listing")
+
+// ListFilesPaged lists path like ListFiles but streams rclone's output,
+// calling fn with up to pageSize names at a time instead of holding the
+// whole listing in memory. Suitable for directories with millions of
+// entries. If fn returns an error the listing stops and that error is
+// returned.
+func ListFilesPaged(ctx context.Context, path string, recursive bool, pageSize int, fn func(page []string) error) error {
+	if pageSize <= 0 {
+		return &ValidationError{Field: "pageSize", Message: "page size must be positive"}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	args := []string{"lsf", path}
+	if !recursive {
+		args = append(args, "--max-depth", "1")
+	}
+
+	listCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cmd := exec.CommandContext(listCtx, "rclone", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to list files: %w", err)
+	}
+
+	var fnErr error
+	page := make([]string, 0, pageSize)
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		name := strings.TrimSpace(scanner.Text())
+		if name == "" {
+			continue
+		}
+		page = append(page, name)
+		if len(page) == pageSize {
+			if fnErr = fn(page); fnErr != nil {
+				break
+			}
+			page = make([]string, 0, pageSize)
+		}
+	}
+	scanErr := scanner.Err()
+	if fnErr == nil && scanErr == nil && len(page) > 0 {
+		fnErr = fn(page)
+	}
+
+	if fnErr != nil || scanErr != nil {
+		// Stop rclone rather than waiting for a listing nobody wants, and
+		// drain the pipe so Wait isn't left blocked on a full buffer.
+		cancel()
+		io.Copy(io.Discard, stdout)
+		cmd.Wait()
+		if fnErr != nil {
+			return fnErr
+		}
+		return fmt.Errorf("failed to read listing: %w", scanErr)
+	}
+
+	if err := cmd.Wait(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, strings.Join(strings.Fields(msg), " "))
+		}
+		return fmt.Errorf("failed to list files: %w", err)
+	}
+	return nil
+}
+
+// LongFileInfo is a single entry from `rclone lsl`
+type LongFileInfo struct {
+	Size int64
+	// ModTime is the zero time when the remote doesn't support modification
+	// times (rclone reports them as 0001-01-01)
+	ModTime time.Time
+	Path    string
+}
+
+// lslRegex matches a line of `rclone lsl` output:
+// "    60295 2016-06-25 18:55:41.062626927 path/to/file"
+var lslRegex = regexp.MustCompile(`^\s*(\d+)\s+(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}(?:\.\d+)?)\s(.*)$`)
+
+// ListFilesLong lists files recursively with their size and modification
+// time using `rclone lsl`
+func ListFilesLong(ctx context.Context, path string) ([]LongFileInfo, error) {
+	output, err := runRclone(ctx, "lsl", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	return parseLslOutput(string(output))
+}
+
+// parseLslOutput parses the columnar output of `rclone lsl`
+func parseLslOutput(output string) ([]LongFileInfo, error) {
+	var files []LongFileInfo
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		matches := lslRegex.FindStringSubmatch(line)
+		if matches == nil {
+			return nil, fmt.Errorf("unexpected lsl output: %q", line)
+		}
+
+		size, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid size in lsl output: %q", line)
+		}
+
+		var modTime time.Time
+		if !strings.HasPrefix(matches[2], "0001-01-01") {
+			modTime, err = time.ParseInLocation("2006-01-02 15:04:05.999999999", matches[2], time.Local)
+			if err != nil {
+				return nil, fmt.Errorf("invalid time in lsl output: %q", line)
+			}
+		}
+
+		files = append(files, LongFileInfo{Size: size, ModTime: modTime, Path: matches[3]})
+	}
+
+	return files, nil
+}
+
+// ParseHashSumOutput parses the output of `rclone md5sum`, `rclone sha1sum`
+// or `rclone hashsum` ("hash  path" per line) into a map of path to hash
+func ParseHashSumOutput(output string) (map[string]string, error) {
+	sums := make(map[string]string)
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		hash, path, ok := strings.Cut(line, "  ")
+		if !ok || hash == "" || path == "" {
+			return nil, fmt.Errorf("unexpected hashsum output: %q", line)
+		}
+		sums[path] = hash
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return sums, nil
+}
+
+// FileInfo holds the metadata rclone reports for a file or directory via
+// `rclone lsjson`
+type FileInfo struct {
+	Path     string
+	Name     string
+	Size     int64 // -1 for directories and unknown sizes
+	MimeType string
+	ModTime  time.Time
+	IsDir    bool
+	// ID is the backend's object ID, where it has one
+	ID string
+	// Hashes is only populated when rclone was asked for them
+	Hashes map[string]string
+	// Metadata is only populated when rclone was asked for it, on backends
+	// that support metadata
+	Metadata map[string]string
+}
+
+// ListOptions filters a ListFilesJSONFiltered listing. Zero values don't
+// filter.
+type ListOptions struct {
+	// Recursive lists subdirectories too (--recursive)
+	Recursive bool
+	// MinSize and MaxSize bound file sizes in bytes (--min-size, --max-size)
+	MinSize int64
+	MaxSize int64
+	// MinAge and MaxAge bound file ages, e.g. "7d" or "2024-01-02"
+	// (--min-age, --max-age)
+	MinAge string
+	MaxAge string
+	// Include and Exclude are filter patterns (--include, --exclude)
+	Include []string
+	Exclude []string
+}
+
+// flags converts the options to rclone flags
+func (o ListOptions) flags() []string {
+	var flags []string
+	if o.Recursive {
+		flags = append(flags, "--recursive")
+	}
+	if o.MinSize > 0 {
+		flags = append(flags, "--min-size", strconv.FormatInt(o.MinSize, 10)+"B")
+	}
+	if o.MaxSize > 0 {
+		flags = append(flags, "--max-size", strconv.FormatInt(o.MaxSize, 10)+"B")
+	}
+	if o.MinAge != "" {
+		flags = append(flags, "--min-age", o.MinAge)
+	}
+	if o.MaxAge != "" {
+		flags = append(flags, "--max-age", o.MaxAge)
+	}
+	for _, pattern := range o.Exclude {
+		flags = append(flags, "--exclude", pattern)
+	}
+	for _, pattern := range o.Include {
+		flags = append(flags, "--include", pattern)
+	}
+	return flags
+}
+
+// ListFilesJSON lists path with `rclone lsjson`, returning each entry's
+// size, modification time, MIME type and so on rather than just its name.
+// It returns an error wrapping ErrNotFound if path doesn't exist.
+func ListFilesJSON(ctx context.Context, path string, recursive bool) ([]FileInfo, error) {
+	return ListFilesJSONFiltered(ctx, path, ListOptions{Recursive: recursive})
+}
+
+// ListFilesJSONFiltered is ListFilesJSON with the entries filtered by opts
+func ListFilesJSONFiltered(ctx context.Context, path string, opts ListOptions) ([]FileInfo, error) {
+	if path == "" {
+		return nil, &ValidationError{Field: "path", Message: "path cannot be empty"}
+	}
+	if opts.MinSize < 0 || opts.MaxSize < 0 {
+		return nil, &ValidationError{Field: "size", Message: "sizes cannot be negative"}
+	}
+
+	args := append([]string{"lsjson"}, opts.flags()...)
+	output, err := runRclone(ctx, append(args, path)...)
+	if err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "not found") {
+			return nil, fmt.Errorf("%w: %s: %v", ErrNotFound, path, err)
+		}
+		return nil, fmt.Errorf("failed to list %s: %w", path, err)
+	}
+
+	var files []FileInfo
+	if err := json.Unmarshal(output, &files); err != nil {
+		return nil, fmt.Errorf("failed to parse lsjson output: %w", err)
+	}
+	return files, nil
+}
+
+// StatFile returns metadata for a single file or directory using `rclone
+// lsjson --stat`, without listing its parent. It returns ErrNotFound if path
+// doesn't exist.
+func StatFile(ctx context.Context, path string) (*FileInfo, error) {
+	return statFile(ctx, path)
+}
+
+// GetMetadata returns the metadata rclone reports for the file at path using
+// `rclone lsjson --stat --metadata`, e.g. content-type and user metadata on
+// S3 or GCS. It returns ErrNotFound if path doesn't exist.
+func GetMetadata(ctx context.Context, path string) (map[string]string, error) {
+	info, err := statFile(ctx, path, "--metadata")
+	if err != nil {
+		return nil, err
+	}
+	if info.Metadata == nil {
+		return map[string]string{}, nil
+	}
+	return info.Metadata, nil
+}
+
+// statFile runs `rclone lsjson --stat` on path with extra flags
+func statFile(ctx context.Context, path string, flags ...string) (*FileInfo, error) {
+	if path == "" {
+		return nil, &ValidationError{Field: "path", Message: "path cannot be empty"}
+	}
+
+	args := append([]string{"lsjson", "--stat"}, flags...)
+	output, err := runRclone(ctx, append(args, path)...)
+	if err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "not found") {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, path)
+		}
+		return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	var info FileInfo
+	if err := json.Unmarshal(output, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse lsjson output: %w", err)
+	}
+	return &info, nil
+}
+
 // ListRemotes lists all configured rclone remotes
+//
+// Deprecated: Use ListRemotesFull, which also returns each remote's type:
+//
+//	remotes, err := ListRemotesFull(ctx)
+//	for _, r := range remotes {
+//		fmt.Println(r.Name, r.Type)
+//	}
 func ListRemotes(ctx context.Context) ([]string, error) {
-	cmd := exec.CommandContext(ctx, "rclone", "listremotes")
-	output, err := cmd.Output()
+	output, err := runRclone(ctx, "listremotes")
 	if err != nil {
 		return nil, fmt.Errorf("failed to list remotes: %w", err)
 	}
@@ -53,6 +422,44 @@ func ListRemotes(ctx context.Context) ([]string, error) {
 	return remotes, nil
 }
 
+// RemoteInfo is a configured remote as reported by `rclone listremotes
+// --long`
+type RemoteInfo struct {
+	Name string
+	Type string
+}
+
+// ListRemotesFull lists all configured rclone remotes along with their
+// backend type
+func ListRemotesFull(ctx context.Context) ([]RemoteInfo, error) {
+	output, err := runRclone(ctx, "listremotes", "--long")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remotes: %w", err)
+	}
+	return parseListRemotesLong(string(output)), nil
+}
+
+// parseListRemotesLong parses "name:   type" lines. Remote names may contain
+// spaces, so the type is taken from the end of the line.
+func parseListRemotesLong(output string) []RemoteInfo {
+	var remotes []RemoteInfo
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		i := strings.LastIndexAny(line, " \t")
+		if i < 0 {
+			if line != "" {
+				remotes = append(remotes, RemoteInfo{Name: strings.TrimSuffix(line, ":")})
+			}
+			continue
+		}
+		remotes = append(remotes, RemoteInfo{
+			Name: strings.TrimSuffix(strings.TrimSpace(line[:i]), ":"),
+			Type: line[i+1:],
+		})
+	}
+	return remotes
+}
+
 // CommandExists checks if a command exists in PATH
 func CommandExists(name string) bool {
 	_, err := exec.LookPath(name)
@@ -61,8 +468,7 @@ func CommandExists(name string) bool {
 
 // GetRcloneVersion returns the rclone version string
 func GetRcloneVersion(ctx context.Context) (string, error) {
-	cmd := exec.CommandContext(ctx, "rclone", "version", "--check=false")
-	output, err := cmd.Output()
+	output, err := runRclone(ctx, "version", "--check=false")
 	if err != nil {
 		return "", fmt.Errorf("failed to get rclone version: %w", err)
 	}
@@ -77,6 +483,9 @@ func GetRcloneVersion(ctx context.Context) (string, error) {
 
 // CheckDuplicates checks if files already exist at the destination
 func CheckDuplicates(ctx context.Context, destination string, filenames []string) (map[string]bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	if len(filenames) == 0 {
 		return make(map[string]bool), nil
 	}
@@ -104,8 +513,64 @@ func CheckDuplicates(ctx context.Context, destination string, filenames []string
 	return duplicates, nil
 }
 
-// IsRemotePath returns true if the path is an rclone remote path (contains :)
+// CheckDuplicatesPaged is CheckDuplicates for very large destinations. It
+// streams the destination listing with ListFilesPaged in pages of pageSize
+// names, so memory use depends on len(filenames) rather than the size of the
+// destination, and stops listing once every filename has been found.
+func CheckDuplicatesPaged(ctx context.Context, destination string, filenames []string, pageSize int) (map[string]bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	duplicates := make(map[string]bool)
+	if len(filenames) == 0 {
+		return duplicates, nil
+	}
+
+	wanted := make(map[string]bool, len(filenames))
+	for _, filename := range filenames {
+		wanted[filename] = true
+	}
+
+	err := ListFilesPaged(ctx, destination, false, pageSize, func(page []string) error {
+		for _, file := range page {
+			if wanted[file] {
+				duplicates[file] = true
+			}
+		}
+		if len(duplicates) == len(wanted) {
+			return errStopListing
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStopListing) {
+		return nil, fmt.Errorf("failed to list destination: %w", err)
+	}
+
+	return duplicates, nil
+}
+
+// driveLetterRegex matches the start of an absolute Windows path, e.g. `C:\`
+var driveLetterRegex = regexp.MustCompile(`^[A-Za-z]:[/\\]`)
+
+// looksLikeDriveLetter reports whether path starts with a Windows drive
+// letter, like `C:\Users` or "d:/data"
+func looksLikeDriveLetter(path string) bool {
+	return driveLetterRegex.MatchString(path)
+}
+
+// isUNCPath reports whether path is a Windows UNC path such as
+// `\\server\share` or `\\?\C:\long\path`
+func isUNCPath(path string) bool {
+	return strings.HasPrefix(path, `\\`) || strings.HasPrefix(path, "//")
+}
+
+// IsRemotePath returns true if the path is an rclone remote path (contains :).
+// On Windows, drive-letter and UNC paths are local even though they may
+// contain a colon, matching how rclone itself treats them.
 func IsRemotePath(path string) bool {
+	if runtime.GOOS == "windows" && (looksLikeDriveLetter(path) || isUNCPath(path)) {
+		return false
+	}
 	return strings.Contains(path, ":")
 }
 
@@ -126,3 +591,62 @@ func JoinRemotePath(remote, path string) string {
 	}
 	return remote + ":" + path
 }
+
+// EnsureDestinationDir creates dir and any missing parents, using
+// `rclone mkdir` for remote paths and os.MkdirAll for local ones
+func EnsureDestinationDir(ctx context.Context, dir string) error {
+	if dir == "" {
+		return &ValidationError{Field: "destination", Message: "directory cannot be empty"}
+	}
+
+	if IsRemotePath(dir) {
+		if _, err := runRclone(ctx, "mkdir", dir); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	return nil
+}
+
+// ensureDestinationParent is the PreExecuteFunc installed by WithAutoMkdir
+func ensureDestinationParent(ctx context.Context, opts RcloneOptions) error {
+	parent := parentPath(opts.Destination)
+	if parent == "" {
+		return nil
+	}
+	return EnsureDestinationDir(ctx, parent)
+}
+
+// parentPath returns the directory containing path, or "" when path is
+// already the root of a remote or the current directory
+func parentPath(path string) string {
+	if IsRemotePath(path) {
+		remote, p := SplitRemotePath(path)
+		p = strings.TrimSuffix(p, "/")
+		i := strings.LastIndex(p, "/")
+		if i < 0 {
+			return ""
+		}
+		return remote + ":" + p[:i]
+	}
+
+	dir := filepath.Dir(path)
+	if dir == "." || dir == path {
+		return ""
+	}
+	return dir
+}
+
+// childPath appends name to a local or remote directory path, taking care
+// not to double up separators ("remote:" + "f" -> "remote:f", "remote:dir" +
+// "f" -> "remote:dir/f")
+func childPath(dir, name string) string {
+	if dir == "" || strings.HasSuffix(dir, ":") || strings.HasSuffix(dir, "/") {
+		return dir + name
+	}
+	return dir + "/" + name
+}
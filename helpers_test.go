@@ -0,0 +1,133 @@
+package rclonelib
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLslOutput(t *testing.T) {
+	output := "    60295 2016-06-25 18:55:41.062626927 bevajer5jef\n" +
+		"        0 0001-01-01 00:00:00.000000000 no modtime/file name.txt\n"
+
+	files, err := parseLslOutput(output)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %d", len(files))
+	}
+
+	want := time.Date(2016, 6, 25, 18, 55, 41, 62626927, time.Local)
+	if files[0].Size != 60295 || files[0].Path != "bevajer5jef" || !files[0].ModTime.Equal(want) {
+		t.Errorf("unexpected first entry: %+v", files[0])
+	}
+	if files[1].Path != "no modtime/file name.txt" || !files[1].ModTime.IsZero() {
+		t.Errorf("expected unsupported modtime to be zero, got %+v", files[1])
+	}
+}
+
+func TestParseHashSumOutput(t *testing.T) {
+	sums, err := ParseHashSumOutput("d41d8cd98f00b204e9800998ecf8427e  empty.txt\n" +
+		"5d41402abc4b2a76b9719d911017c592  dir/hello world.txt\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sums["dir/hello world.txt"] != "5d41402abc4b2a76b9719d911017c592" {
+		t.Errorf("unexpected sums: %v", sums)
+	}
+
+	if _, err := ParseHashSumOutput("not-a-hashsum-line"); err == nil {
+		t.Error("expected error for malformed line")
+	}
+}
+
+func TestHelpers_CancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := ListFiles(ctx, "remote:path", false); !errors.Is(err, context.Canceled) {
+		t.Errorf("ListFiles: expected context.Canceled, got %v", err)
+	}
+	if _, err := ListRemotes(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("ListRemotes: expected context.Canceled, got %v", err)
+	}
+	if _, err := GetRcloneVersion(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetRcloneVersion: expected context.Canceled, got %v", err)
+	}
+	if _, err := CheckDuplicates(ctx, "remote:path", []string{"a"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("CheckDuplicates: expected context.Canceled, got %v", err)
+	}
+	if _, err := CheckDuplicatesPaged(ctx, "remote:path", []string{"a"}, 100); !errors.Is(err, context.Canceled) {
+		t.Errorf("CheckDuplicatesPaged: expected context.Canceled, got %v", err)
+	}
+}
+
+func TestParseListRemotesLong(t *testing.T) {
+	output := "gdrive:      drive\nmy s3:       s3\nlocal:       local\n\n"
+
+	remotes := parseListRemotesLong(output)
+	want := []RemoteInfo{{"gdrive", "drive"}, {"my s3", "s3"}, {"local", "local"}}
+	if len(remotes) != len(want) {
+		t.Fatalf("got %d remotes, want %d: %+v", len(remotes), len(want), remotes)
+	}
+	for i := range want {
+		if remotes[i] != want[i] {
+			t.Errorf("remote %d = %+v, want %+v", i, remotes[i], want[i])
+		}
+	}
+}
+
+func TestParseConfigFilePath(t *testing.T) {
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{"Configuration file is stored at:\n/home/user/.config/rclone/rclone.conf\n", "/home/user/.config/rclone/rclone.conf"},
+		{"Configuration file doesn't exist, but rclone will use this path:\n/root/.config/rclone/rclone.conf\n", "/root/.config/rclone/rclone.conf"},
+	}
+	for _, tt := range tests {
+		got, err := parseConfigFilePath(tt.output)
+		if err != nil || got != tt.want {
+			t.Errorf("parseConfigFilePath(%q) = %q, %v; want %q", tt.output, got, err, tt.want)
+		}
+	}
+
+	if _, err := parseConfigFilePath(""); err == nil {
+		t.Error("expected error for empty output")
+	}
+}
+
+func TestListOptionsFlags(t *testing.T) {
+	opts := ListOptions{Recursive: true, MinSize: 1024, MaxAge: "7d", Include: []string{"*.mkv"}}
+	got := strings.Join(opts.flags(), " ")
+	want := "--recursive --min-size 1024B --max-age 7d --include *.mkv"
+	if got != want {
+		t.Errorf("flags() = %q, want %q", got, want)
+	}
+
+	if _, err := ListFilesJSONFiltered(context.Background(), "", ListOptions{}); err == nil {
+		t.Error("expected an error for an empty path")
+	}
+}
+
+func TestIsUNCPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{`\\server\share\file.txt`, true},
+		{`\\?\C:\very\long\path`, true},
+		{`//server/share`, true},
+		{`C:\Users\file.txt`, false},
+		{`\single`, false},
+		{"remote:path", false},
+	}
+	for _, tt := range tests {
+		if got := isUNCPath(tt.path); got != tt.want {
+			t.Errorf("isUNCPath(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
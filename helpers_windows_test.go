@@ -0,0 +1,26 @@
+package rclonelib
+
+import "testing"
+
+func TestIsRemotePath_Windows(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{`C:\Users\file.txt`, false},
+		{`d:/data/file.txt`, false},
+		{`\\server\share\file.txt`, false},
+		{`\\?\C:\very\long\path`, false},
+		{`//server/share/file.txt`, false},
+		{`relative\path`, false},
+		{"remote:path/file.txt", true},
+		{"remote:", true},
+		{"s3:bucket", true},
+	}
+
+	for _, tt := range tests {
+		if got := IsRemotePath(tt.path); got != tt.want {
+			t.Errorf("IsRemotePath(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
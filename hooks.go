@@ -0,0 +1,24 @@
+package rclonelib
+
+import "time"
+
+// ExecutionHooks are callbacks an Executor invokes synchronously around each
+// run of rclone, for logging, metrics or tracing. Any of them may be nil.
+// They run on the calling goroutine, so they should return quickly.
+type ExecutionHooks struct {
+	// BeforeExecute is called at the start of every Execute, including each
+	// attempt made by ExecuteWithRetry
+	BeforeExecute func(id string, opts RcloneOptions)
+	// AfterExecute is called when Execute returns, with its error
+	AfterExecute func(id string, opts RcloneOptions, err error)
+	// BeforeRetry is called by ExecuteWithRetry before it sleeps, with the
+	// number of the attempt about to be made and how long it will wait
+	BeforeRetry func(id string, attempt int, delay time.Duration)
+}
+
+// WithHooks installs hooks on an Executor
+func WithHooks(hooks ExecutionHooks) ExecutorOption {
+	return func(e *Executor) {
+		e.hooks = hooks
+	}
+}
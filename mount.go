@@ -0,0 +1,320 @@
+package rclonelib
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mount lifecycle events reported on MountPoint.Events
+const (
+	MountEventMounted       = "Mounted"
+	MountEventUnmounted     = "Unmounted"
+	MountEventRemounting    = "Remounting"
+	MountEventRemountFailed = "RemountFailed"
+)
+
+// defaultMountCheckInterval is how often WatchAndRemount checks the mount
+const defaultMountCheckInterval = 5 * time.Second
+
+// mountReadyTimeout bounds how long Mount waits for the mount to appear
+const mountReadyTimeout = 10 * time.Second
+
+// MountEvent records a change in a MountPoint's state
+type MountEvent struct {
+	// Event is one of the MountEvent* constants
+	Event string
+	At    time.Time
+}
+
+// MountPoint runs `rclone mount` in the background. Mounting needs FUSE
+// (macFUSE on macOS, WinFsp on Windows).
+type MountPoint struct {
+	Remote string
+	// Dir is the local directory (or drive letter on Windows) to mount on
+	Dir string
+	// Flags are additional flags to pass to rclone, e.g. "--vfs-cache-mode",
+	// "writes"
+	Flags []string
+
+	// RetryConfig sets the back-off between remount attempts in
+	// WatchAndRemount (default: DefaultRetryConfig)
+	RetryConfig RetryConfig
+	// CheckInterval is how often WatchAndRemount checks the mount (default:
+	// 5s)
+	CheckInterval time.Duration
+
+	// Events receives mount lifecycle events. It is buffered and events are
+	// dropped if nobody is reading.
+	Events chan MountEvent
+
+	mu sync.Mutex
+	// ctx is the context passed to Mount. Remounts run under it rather than
+	// WatchAndRemount's, so stopping the watcher doesn't unmount.
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+	stderr bytes.Buffer
+	// stopWatch and watchDone are set while WatchAndRemount is running
+	stopWatch context.CancelFunc
+	watchDone chan struct{}
+}
+
+// NewMountPoint returns a mount of remote on dir. Call Mount to mount it.
+func NewMountPoint(remote, dir string, flags ...string) *MountPoint {
+	return &MountPoint{
+		Remote:      remote,
+		Dir:         dir,
+		Flags:       flags,
+		RetryConfig: DefaultRetryConfig(),
+		Events:      make(chan MountEvent, eventBufferSize),
+	}
+}
+
+// Mount starts rclone and waits until the mount is visible at Dir. rclone
+// keeps running until Unmount is called or ctx is cancelled.
+func (mp *MountPoint) Mount(ctx context.Context) error {
+	return mp.mount(ctx, true)
+}
+
+// mount starts rclone under ctx, remembering ctx for later remounts if
+// remember is set
+func (mp *MountPoint) mount(ctx context.Context, remember bool) error {
+	if mp.Remote == "" {
+		return &ValidationError{Field: "remote", Message: "remote cannot be empty"}
+	}
+	if mp.Dir == "" {
+		return &ValidationError{Field: "dir", Message: "mount directory cannot be empty"}
+	}
+
+	mp.mu.Lock()
+	if mp.done != nil {
+		select {
+		case <-mp.done:
+		default:
+			mp.mu.Unlock()
+			return errors.New("already mounted")
+		}
+	}
+
+	args := append([]string{"mount", mp.Remote, mp.Dir}, mp.Flags...)
+	runCtx, cancel := context.WithCancel(ctx)
+	cmd := exec.CommandContext(runCtx, "rclone", args...)
+	if runtime.GOOS != "windows" {
+		// Let rclone unmount cleanly rather than leaving a stale FUSE mount
+		cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+		cmd.WaitDelay = mountReadyTimeout
+	}
+	mp.stderr.Reset()
+	cmd.Stderr = &mp.stderr
+	if err := cmd.Start(); err != nil {
+		mp.mu.Unlock()
+		cancel()
+		return fmt.Errorf("failed to start rclone mount: %w", err)
+	}
+
+	done := make(chan struct{})
+	mp.cancel, mp.done, mp.err = cancel, done, nil
+	if remember {
+		mp.ctx = ctx
+	}
+	go func() {
+		err := cmd.Wait()
+		mp.mu.Lock()
+		mp.err = err
+		mp.mu.Unlock()
+		close(done)
+	}()
+	mp.mu.Unlock()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.NewTimer(mountReadyTimeout)
+	defer timeout.Stop()
+
+	for !mp.IsMounted() {
+		select {
+		case <-ctx.Done():
+			mp.unmount()
+			return ctx.Err()
+		case <-done:
+			return fmt.Errorf("rclone mount exited: %w", mp.exitErr())
+		case <-timeout.C:
+			mp.unmount()
+			return fmt.Errorf("%s did not appear mounted within %s", mp.Dir, mountReadyTimeout)
+		case <-ticker.C:
+		}
+	}
+
+	mp.emit(MountEventMounted)
+	return nil
+}
+
+// IsMounted reports whether a filesystem is currently mounted at Dir
+func (mp *MountPoint) IsMounted() bool {
+	return isMountPoint(mp.Dir)
+}
+
+// Unmount stops WatchAndRemount, if it is running, and rclone, waiting for
+// both to exit, then unmounts Dir with fusermount or umount in case rclone
+// didn't, e.g. because it had already died and left a stale FUSE mount
+// behind
+func (mp *MountPoint) Unmount() error {
+	mp.mu.Lock()
+	stopWatch, watchDone := mp.stopWatch, mp.watchDone
+	mp.mu.Unlock()
+	if stopWatch != nil {
+		stopWatch()
+		<-watchDone
+	}
+
+	return mp.unmount()
+}
+
+// unmount is Unmount without stopping the watcher, for use while remounting
+func (mp *MountPoint) unmount() error {
+	mp.mu.Lock()
+	cancel, done := mp.cancel, mp.done
+	mp.mu.Unlock()
+	if done == nil {
+		return nil
+	}
+
+	cancel()
+	<-done
+
+	// A directory that wasn't mounted makes the unmount command fail too, so
+	// only report failures that leave something mounted.
+	if err := unmountDir(mp.Dir); err != nil && mp.IsMounted() {
+		return fmt.Errorf("failed to unmount %s: %w", mp.Dir, err)
+	}
+	return nil
+}
+
+// WatchAndRemount checks the mount every CheckInterval until ctx is done and
+// mounts it again if it has disappeared, e.g. after a network error, making
+// up to maxAttempts attempts with RetryConfig's back-off. If every attempt
+// fails a RemountFailed event is sent and watching stops. Unmount also stops
+// it. Mount must have succeeded first. It returns immediately; progress is
+// reported on Events.
+func (mp *MountPoint) WatchAndRemount(ctx context.Context, maxAttempts int) error {
+	if maxAttempts <= 0 {
+		return &ValidationError{Field: "maxAttempts", Message: "must be at least 1"}
+	}
+
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	if mp.done == nil {
+		return errors.New("not mounted")
+	}
+	if mp.watchDone != nil {
+		return errors.New("already watching")
+	}
+	watchCtx, stop := context.WithCancel(ctx)
+	watchDone := make(chan struct{})
+	mp.stopWatch, mp.watchDone = stop, watchDone
+
+	go func() {
+		defer func() {
+			mp.mu.Lock()
+			mp.stopWatch, mp.watchDone = nil, nil
+			mp.mu.Unlock()
+			stop()
+			close(watchDone)
+		}()
+		mp.watch(watchCtx, maxAttempts)
+	}()
+	return nil
+}
+
+func (mp *MountPoint) watch(ctx context.Context, maxAttempts int) {
+	interval := mp.CheckInterval
+	if interval <= 0 {
+		interval = defaultMountCheckInterval
+	}
+	retry := mp.RetryConfig.withDefaults()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if mp.IsMounted() {
+			continue
+		}
+
+		mp.emit(MountEventUnmounted)
+		if !mp.remount(ctx, maxAttempts, retry) {
+			return
+		}
+	}
+}
+
+// remount makes up to maxAttempts attempts to mount again, reporting whether
+// one succeeded. ctx only stops the attempts: the new rclone runs under the
+// context Mount was given.
+func (mp *MountPoint) remount(ctx context.Context, maxAttempts int, retry RetryConfig) bool {
+	mp.mu.Lock()
+	mountCtx := mp.ctx
+	mp.mu.Unlock()
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return false
+			case <-time.After(retry.delay(attempt - 1)):
+			}
+		}
+
+		mp.emit(MountEventRemounting)
+		mp.unmount()
+		if err := mp.mount(mountCtx, false); err == nil {
+			return true
+		}
+		if ctx.Err() != nil || mountCtx.Err() != nil {
+			return false
+		}
+	}
+
+	mp.emit(MountEventRemountFailed)
+	return false
+}
+
+// emit sends an event without blocking
+func (mp *MountPoint) emit(event string) {
+	select {
+	case mp.Events <- MountEvent{Event: event, At: time.Now()}:
+	default:
+	}
+}
+
+// exitErr describes why rclone exited, including its stderr
+func (mp *MountPoint) exitErr() error {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+
+	msg := strings.Join(strings.Fields(mp.stderr.String()), " ")
+	switch {
+	case mp.err != nil && msg != "":
+		return fmt.Errorf("%w: %s", mp.err, msg)
+	case mp.err != nil:
+		return mp.err
+	case msg != "":
+		return errors.New(msg)
+	default:
+		return errors.New("exited without error")
+	}
+}
@@ -0,0 +1,37 @@
+package rclonelib
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMountPoint_UnmountStopsWatcher(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "calls")
+	fakeRclone(t, `echo "$@" >> "`+log+`"`+"\n")
+
+	// Pretend a mount is running so WatchAndRemount can start
+	done := make(chan struct{})
+	close(done)
+	mp := NewMountPoint("remote:", t.TempDir())
+	mp.CheckInterval = 10 * time.Millisecond
+	mp.ctx, mp.cancel, mp.done = context.Background(), func() {}, done
+
+	if err := mp.WatchAndRemount(context.Background(), 1); err != nil {
+		t.Fatalf("WatchAndRemount: %v", err)
+	}
+	if err := mp.Unmount(); err != nil {
+		t.Fatalf("Unmount: %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if calls, err := os.ReadFile(log); err == nil {
+		t.Errorf("watcher remounted after Unmount: %s", calls)
+	}
+	if err := mp.WatchAndRemount(context.Background(), 1); err != nil {
+		t.Errorf("watcher still registered after Unmount: %v", err)
+	}
+	mp.Unmount()
+}
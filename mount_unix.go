@@ -0,0 +1,57 @@
+//go:build !windows
+// +build !windows
+
+package rclonelib
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+)
+
+// isMountPoint reports whether dir is on a different device from its parent,
+// which is the case while a filesystem is mounted on it
+func isMountPoint(dir string) bool {
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return false
+	}
+	parent, err := os.Stat(filepath.Dir(filepath.Clean(dir)))
+	if err != nil {
+		return false
+	}
+
+	st, ok1 := info.Sys().(*syscall.Stat_t)
+	pst, ok2 := parent.Sys().(*syscall.Stat_t)
+	if !ok1 || !ok2 {
+		return false
+	}
+	return st.Dev != pst.Dev
+}
+
+// unmountDir unmounts the filesystem on dir: with fusermount on Linux, where
+// FUSE mounts belong to the user, and umount elsewhere
+func unmountDir(dir string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "linux" {
+		name := "fusermount"
+		if _, err := exec.LookPath(name); err != nil {
+			name = "fusermount3"
+		}
+		cmd = exec.Command(name, "-u", dir)
+	} else {
+		cmd = exec.Command("umount", dir)
+	}
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+//go:build windows
+// +build windows
+
+package rclonelib
+
+import "os"
+
+// isMountPoint reports whether dir exists. WinFsp creates the mount's drive
+// letter or directory when mounting and removes it when unmounting, so it
+// only exists while mounted.
+func isMountPoint(dir string) bool {
+	_, err := os.Stat(dir)
+	return err == nil
+}
+
+// unmountDir does nothing: WinFsp removes the mount when rclone exits
+func unmountDir(dir string) error {
+	return nil
+}
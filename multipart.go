@@ -0,0 +1,66 @@
+package rclonelib
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// MultipartProgress tracks the parts of a chunked upload
+type MultipartProgress struct {
+	CompletedParts int
+	TotalParts     int
+}
+
+// S3MultipartParser recognises rclone's per-part messages for chunked
+// uploads, both "Multipart: Part [3/10]" and the multi-thread form
+// "multi-thread copy: chunk 3/10 (0-5242880) size 5Mi finished". rclone
+// only logs these at debug level, so pass -vv to see them.
+type S3MultipartParser struct{}
+
+// multipartRegex matches a completed part and the total number of parts.
+// Multi-thread copies log each chunk twice, "starting" and "finished", so
+// only the second counts; \b keeps "chunker" backend lines from matching.
+var multipartRegex = regexp.MustCompile(`(?i)\bmultipart:\s*part\s*\[(\d+)\s*/\s*(\d+)\]|\bchunk\s+(\d+)\s*/\s*(\d+)\b.*\bfinished\b`)
+
+// ParseLine reports whether line announces a completed part, and if so
+// which part and out of how many
+func (S3MultipartParser) ParseLine(line string) (part, total int, ok bool) {
+	matches := multipartRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return 0, 0, false
+	}
+	if matches[1] == "" {
+		matches = matches[2:]
+	}
+	part, err1 := strconv.Atoi(matches[1])
+	total, err2 := strconv.Atoi(matches[2])
+	if err1 != nil || err2 != nil || total <= 0 {
+		return 0, 0, false
+	}
+	return part, total, true
+}
+
+// IsMultipart reports whether the transfer is being uploaded in several
+// parts
+func (t *Transfer) IsMultipart() bool {
+	return t.Multipart != nil && t.Multipart.TotalParts > 1
+}
+
+// recordMultipartPart counts one more completed part for a transfer. Parts
+// can finish out of order, so completions are counted rather than taken
+// from the part number.
+func (m *Manager) recordMultipartPart(id string, total int) {
+	m.update(id, func(t *Transfer) error {
+		// Replace rather than modify, since notifiers hold copies of the
+		// transfer that share the pointer.
+		progress := MultipartProgress{TotalParts: total}
+		if t.Multipart != nil && t.Multipart.TotalParts == total {
+			progress = *t.Multipart
+		}
+		if progress.CompletedParts < total {
+			progress.CompletedParts++
+		}
+		t.Multipart = &progress
+		return nil
+	})
+}
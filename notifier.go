@@ -0,0 +1,35 @@
+package rclonelib
+
+// Notifier is informed whenever a transfer tracked by a Manager changes
+type Notifier interface {
+	// Notify receives a copy of the transfer after the change. It is called
+	// synchronously with the manager's lock released, so it may call back
+	// into the Manager but should return quickly.
+	Notify(t Transfer)
+}
+
+// NotifierFunc adapts an ordinary function to the Notifier interface
+type NotifierFunc func(t Transfer)
+
+// Notify calls f(t)
+func (f NotifierFunc) Notify(t Transfer) {
+	f(t)
+}
+
+// AddNotifier registers n to be told about every subsequent change
+func (m *Manager) AddNotifier(n Notifier) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Copy on write so notifyAll can iterate a snapshot without the lock.
+	notifiers := make([]Notifier, 0, len(m.notifiers)+1)
+	notifiers = append(notifiers, m.notifiers...)
+	m.notifiers = append(notifiers, n)
+}
+
+// notifyAll delivers t to each notifier in registration order
+func notifyAll(notifiers []Notifier, t Transfer) {
+	for _, n := range notifiers {
+		n.Notify(t)
+	}
+}
@@ -0,0 +1,256 @@
+package rclonelib
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DeleteOptions configures Delete
+type DeleteOptions struct {
+	// MinAge only deletes files older than this (e.g. "30d")
+	MinAge string
+	// MaxAge only deletes files younger than this
+	MaxAge string
+	// Exclude patterns to leave alone
+	Exclude []string
+	// Include patterns to delete
+	Include []string
+	// DryRun reports what would be deleted without deleting anything
+	DryRun bool
+}
+
+// PurgeResult describes the outcome of Purge
+type PurgeResult struct {
+	// FilesDeleted is the number of files rclone reported deleting. Backends
+	// that purge a directory in a single call don't report individual files.
+	FilesDeleted int
+}
+
+// DeleteResult describes the outcome of Delete
+type DeleteResult struct {
+	FilesDeleted int
+	// BytesDeleted is only known for dry runs, where rclone reports the size
+	// of each file it skips
+	BytesDeleted int64
+}
+
+var (
+	// deletedRegex matches rclone's per-file delete log lines, both real
+	// ("file: Deleted") and simulated ("file: Skipped delete as --dry-run is
+	// set (size 1.234Ki)")
+	deletedRegex = regexp.MustCompile(`: (?:Deleted|Skipped delete as --dry-run is set)(?: \(size ([0-9.]+)\s*([kKMGTP]?i?[Bb]?)\))?`)
+)
+
+// Purge removes path and all of its contents using `rclone purge`. Unlike
+// Delete it ignores filters and removes directories too. With dryRun set
+// rclone only reports what it would remove; use PurgeDryRun to get the count.
+//
+// When dryRun is false, purging the root of a remote or filesystem is refused
+// with ErrDestructiveOperation.
+func Purge(ctx context.Context, path string, dryRun bool) error {
+	_, err := purge(ctx, path, dryRun)
+	return err
+}
+
+// PurgeDryRun reports how many files Purge would remove from path without
+// removing anything
+func PurgeDryRun(ctx context.Context, path string) (*PurgeResult, error) {
+	return purge(ctx, path, true)
+}
+
+func purge(ctx context.Context, path string, dryRun bool) (*PurgeResult, error) {
+	if path == "" {
+		return nil, &ValidationError{Field: "path", Message: "path cannot be empty"}
+	}
+	if !dryRun && isRootPath(path) {
+		return nil, fmt.Errorf("%w: purge of %s", ErrDestructiveOperation, path)
+	}
+
+	args := []string{"purge", "-v", path}
+	if dryRun {
+		args = append(args, "--dry-run")
+	}
+
+	output, err := runRcloneCombined(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge %s: %w", path, err)
+	}
+
+	files, _ := countDeleted(string(output))
+	return &PurgeResult{FilesDeleted: files}, nil
+}
+
+// Delete removes the files in path matching opts using `rclone delete`,
+// leaving the directory structure in place. With opts.DryRun set rclone only
+// reports what it would remove; use DeleteDryRun to get the counts.
+//
+// When opts.DryRun is false, deleting from the root of a remote or
+// filesystem is refused with ErrDestructiveOperation, filters or not.
+func Delete(ctx context.Context, path string, opts DeleteOptions) error {
+	_, err := deleteFiles(ctx, path, opts)
+	return err
+}
+
+// DeleteDryRun reports how many files, and how many bytes, Delete would
+// remove from path without removing anything. opts.DryRun is ignored.
+func DeleteDryRun(ctx context.Context, path string, opts DeleteOptions) (*DeleteResult, error) {
+	opts.DryRun = true
+	return deleteFiles(ctx, path, opts)
+}
+
+func deleteFiles(ctx context.Context, path string, opts DeleteOptions) (*DeleteResult, error) {
+	if path == "" {
+		return nil, &ValidationError{Field: "path", Message: "path cannot be empty"}
+	}
+	if !opts.DryRun && isRootPath(path) {
+		return nil, fmt.Errorf("%w: delete from %s", ErrDestructiveOperation, path)
+	}
+
+	args := []string{"delete", "-v", path}
+	if opts.MinAge != "" {
+		args = append(args, "--min-age", opts.MinAge)
+	}
+	if opts.MaxAge != "" {
+		args = append(args, "--max-age", opts.MaxAge)
+	}
+	for _, pattern := range opts.Exclude {
+		args = append(args, "--exclude", pattern)
+	}
+	for _, pattern := range opts.Include {
+		args = append(args, "--include", pattern)
+	}
+	if opts.DryRun {
+		args = append(args, "--dry-run")
+	}
+
+	output, err := runRcloneCombined(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete from %s: %w", path, err)
+	}
+
+	files, bytes := countDeleted(string(output))
+	return &DeleteResult{FilesDeleted: files, BytesDeleted: bytes}, nil
+}
+
+// countDeleted counts the files rclone reported deleting (or skipping in a
+// dry run) and totals their sizes where rclone included them
+func countDeleted(output string) (files int, bytes int64) {
+	for _, line := range strings.Split(output, "\n") {
+		matches := deletedRegex.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		files++
+		if matches[1] != "" {
+			bytes += parseSize(matches[1], matches[2])
+		}
+	}
+	return files, bytes
+}
+
+// isRootPath reports whether path is the root of a remote ("remote:",
+// "remote:/") or of a local filesystem ("/", "C:\")
+func isRootPath(path string) bool {
+	if IsRemotePath(path) {
+		_, p := SplitRemotePath(path)
+		p = strings.Trim(p, "/")
+		return p == "" || p == "."
+	}
+
+	clean := filepath.Clean(path)
+	return clean == filepath.Dir(clean)
+}
+
+// MoveOptions configures Move. The embedded RcloneOptions is honoured as by
+// Execute, except that its Command, Source and Destination are set by Move
+// and a Context, if set, replaces the one passed to Move.
+type MoveOptions struct {
+	RcloneOptions
+	// DeleteEmptySrcDirs removes source directories left empty by the move
+	DeleteEmptySrcDirs bool
+}
+
+// MoveResult describes the outcome of Move
+type MoveResult struct {
+	FilesMoved  int
+	DirsDeleted int
+}
+
+var (
+	// movedFilesRegex matches the file-count line of rclone's final stats,
+	// e.g. "Transferred:            3 / 3, 100%" (as opposed to the byte line,
+	// which carries units, speed and ETA)
+	movedFilesRegex = regexp.MustCompile(`Transferred:\s+(\d+)\s*/\s*\d+,\s*\d+%\s*$`)
+	// renamedRegex matches the server-side move count, e.g. "Renamed: 3"
+	renamedRegex = regexp.MustCompile(`Renamed:\s+(\d+)\s*$`)
+	// deletedDirsRegex matches "Deleted: 3 (files), 2 (dirs)"
+	deletedDirsRegex = regexp.MustCompile(`Deleted:\s+\d+ \(files\), (\d+) \(dirs\)`)
+)
+
+// Move moves the contents of src to dst using `rclone move` and reports how
+// many files were moved and, with DeleteEmptySrcDirs, how many emptied source
+// directories were removed.
+func Move(ctx context.Context, src, dst string, opts MoveOptions) (*MoveResult, error) {
+	if opts.Context != nil {
+		ctx = opts.Context
+	}
+	if err := ValidateStatsInterval(opts.StatsInterval); err != nil {
+		return nil, err
+	}
+
+	rcloneOpts := opts.RcloneOptions
+	rcloneOpts.Command = RcloneMove
+	rcloneOpts.Source = src
+	rcloneOpts.Destination = dst
+	rcloneOpts.Flags = append([]string(nil), opts.Flags...)
+	if opts.DeleteEmptySrcDirs {
+		rcloneOpts.Flags = append(rcloneOpts.Flags, "--delete-empty-src-dirs")
+	}
+
+	for _, hook := range rcloneOpts.PreExecute {
+		if err := hook(ctx, rcloneOpts); err != nil {
+			return nil, fmt.Errorf("pre-execute check failed: %w", err)
+		}
+	}
+
+	var env []string
+	if len(opts.Env) > 0 || opts.ClearEnv {
+		env = buildEnv(opts.Env, opts.ClearEnv)
+	}
+	output, err := runRcloneCombinedEnv(ctx, env, BuildRcloneArgs(rcloneOpts)...)
+	if err := opts.ExitCodePolicy.apply(err); err != nil {
+		return nil, fmt.Errorf("failed to move %s to %s: %w", src, dst, err)
+	}
+
+	return parseMoveOutput(string(output)), nil
+}
+
+// parseMoveOutput reads the counts from the last stats block rclone printed.
+// Files moved server-side are reported as renamed; the rest as transferred.
+func parseMoveOutput(output string) *MoveResult {
+	var transferred, renamed, dirs int
+	for _, line := range strings.Split(output, "\n") {
+		if m := movedFilesRegex.FindStringSubmatch(line); m != nil {
+			fmt.Sscanf(m[1], "%d", &transferred)
+		}
+		if m := renamedRegex.FindStringSubmatch(line); m != nil {
+			fmt.Sscanf(m[1], "%d", &renamed)
+		}
+		if m := deletedDirsRegex.FindStringSubmatch(line); m != nil {
+			fmt.Sscanf(m[1], "%d", &dirs)
+		}
+	}
+	return &MoveResult{FilesMoved: transferred + renamed, DirsDeleted: dirs}
+}
+
+// MoveFile moves a single file to an exact destination path using
+// `rclone moveto`
+func MoveFile(ctx context.Context, src, dst string) error {
+	if _, err := runRclone(ctx, string(RcloneMoveTo), src, dst); err != nil {
+		return fmt.Errorf("failed to move %s to %s: %w", src, dst, err)
+	}
+	return nil
+}
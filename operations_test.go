@@ -0,0 +1,46 @@
+package rclonelib
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMove_HonoursRcloneOptions(t *testing.T) {
+	// Exit 9 (nothing transferred) unless the environment came through
+	fakeRclone(t, `[ "$MOVE_TEST" = "yes" ] || exit 9
+echo "Transferred:            2 / 2, 100%"
+exit 3
+`)
+
+	var hookRan bool
+	opts := MoveOptions{RcloneOptions: RcloneOptions{
+		Env:            map[string]string{"MOVE_TEST": "yes"},
+		ExitCodePolicy: &ExitCodePolicy{TreatAsSuccess: []int{3}},
+		PreExecute: []PreExecuteFunc{func(ctx context.Context, opts RcloneOptions) error {
+			hookRan = opts.Command == RcloneMove && opts.Source == "/src"
+			return nil
+		}},
+	}}
+	result, err := Move(context.Background(), "/src", "remote:dst", opts)
+	if err != nil {
+		t.Fatalf("Move: %v", err)
+	}
+	if !hookRan || result.FilesMoved != 2 {
+		t.Errorf("hook ran = %v, result = %+v", hookRan, result)
+	}
+
+	stop := errors.New("stop")
+	opts.PreExecute = []PreExecuteFunc{func(context.Context, RcloneOptions) error { return stop }}
+	if _, err := Move(context.Background(), "/src", "remote:dst", opts); !errors.Is(err, stop) {
+		t.Errorf("expected the pre-execute error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	opts.PreExecute = nil
+	opts.Context = ctx
+	if _, err := Move(context.Background(), "/src", "remote:dst", opts); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected opts.Context to be used, got %v", err)
+	}
+}
@@ -1,6 +1,16 @@
 package rclonelib
 
-import "time"
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // CommonFlags provides commonly used rclone flags
 type CommonFlags struct {
@@ -12,10 +22,22 @@ type CommonFlags struct {
 	Bandwidth int
 	// IgnoreChecksum skips checksum verification for faster transfers
 	IgnoreChecksum bool
+	// Checksum compares files by checksum rather than modification time and
+	// size (--checksum)
+	Checksum bool
+	// ChecksumAlgorithm is the hash ("md5", "sha1", "sha256", "dropbox", ...)
+	// the transfer is expected to be verified with. rclone always picks the
+	// strongest hash both ends have in common, so this is not passed to rclone;
+	// Validate checks it is only set together with Checksum, and
+	// SupportedChecksums can confirm both ends support it.
+	ChecksumAlgorithm string
 	// NoTraverse disables directory traversal optimization
 	NoTraverse bool
 	// Progress shows progress during transfer (-P)
 	Progress bool
+	// ProgressTerminalTitle shows progress in the terminal title
+	// (--progress-terminal-title); only has an effect together with Progress
+	ProgressTerminalTitle bool
 	// Verbose enables verbose output (-v)
 	Verbose bool
 	// Exclude patterns to exclude from transfer
@@ -26,6 +48,10 @@ type CommonFlags struct {
 	MinAge string
 	// MaxAge only transfer files younger than this
 	MaxAge string
+	// UseEnv passes these settings to rclone as RCLONE_* environment
+	// variables (see ToEnvironment) instead of command-line flags, so they
+	// don't show up in process listings
+	UseEnv bool
 }
 
 // ToFlags converts CommonFlags to rclone command-line flags
@@ -33,23 +59,29 @@ func (f CommonFlags) ToFlags() []string {
 	var flags []string
 
 	if f.Transfers > 0 {
-		flags = append(flags, "--transfers", formatInt(f.Transfers))
+		flags = append(flags, "--transfers", strconv.Itoa(f.Transfers))
 	}
 	if f.Checkers > 0 {
-		flags = append(flags, "--checkers", formatInt(f.Checkers))
+		flags = append(flags, "--checkers", strconv.Itoa(f.Checkers))
 	}
 	if f.Bandwidth > 0 {
-		flags = append(flags, "--bwlimit", formatInt(f.Bandwidth)+"k")
+		flags = append(flags, "--bwlimit", strconv.Itoa(f.Bandwidth)+"k")
 	}
 	if f.IgnoreChecksum {
 		flags = append(flags, "--ignore-checksum")
 	}
+	if f.Checksum {
+		flags = append(flags, "--checksum")
+	}
 	if f.NoTraverse {
 		flags = append(flags, "--no-traverse")
 	}
 	if f.Progress {
 		flags = append(flags, "-P")
 	}
+	if f.ProgressTerminalTitle {
+		flags = append(flags, "--progress-terminal-title")
+	}
 	if f.Verbose {
 		flags = append(flags, "-v")
 	}
@@ -71,24 +103,222 @@ func (f CommonFlags) ToFlags() []string {
 	return flags
 }
 
+// ToEnvironment converts CommonFlags to the RCLONE_* environment variables
+// rclone reads in place of the equivalent flags, e.g. Transfers becomes
+// RCLONE_TRANSFERS. Include and Exclude patterns are comma-separated, as
+// rclone expects for repeatable flags.
+func (f CommonFlags) ToEnvironment() map[string]string {
+	env := make(map[string]string)
+
+	if f.Transfers > 0 {
+		env["RCLONE_TRANSFERS"] = strconv.Itoa(f.Transfers)
+	}
+	if f.Checkers > 0 {
+		env["RCLONE_CHECKERS"] = strconv.Itoa(f.Checkers)
+	}
+	if f.Bandwidth > 0 {
+		env["RCLONE_BWLIMIT"] = strconv.Itoa(f.Bandwidth) + "k"
+	}
+	if f.IgnoreChecksum {
+		env["RCLONE_IGNORE_CHECKSUM"] = "true"
+	}
+	if f.Checksum {
+		env["RCLONE_CHECKSUM"] = "true"
+	}
+	if f.NoTraverse {
+		env["RCLONE_NO_TRAVERSE"] = "true"
+	}
+	if f.Progress {
+		env["RCLONE_PROGRESS"] = "true"
+	}
+	if f.ProgressTerminalTitle {
+		env["RCLONE_PROGRESS_TERMINAL_TITLE"] = "true"
+	}
+	if f.Verbose {
+		env["RCLONE_VERBOSE"] = "1"
+	}
+
+	if len(f.Exclude) > 0 {
+		env["RCLONE_EXCLUDE"] = csvJoin(f.Exclude)
+	}
+	if len(f.Include) > 0 {
+		env["RCLONE_INCLUDE"] = csvJoin(f.Include)
+	}
+
+	if f.MinAge != "" {
+		env["RCLONE_MIN_AGE"] = f.MinAge
+	}
+	if f.MaxAge != "" {
+		env["RCLONE_MAX_AGE"] = f.MaxAge
+	}
+
+	return env
+}
+
+// csvJoin joins values as a CSV record, quoting any that contain commas
+func csvJoin(values []string) string {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	w.Write(values)
+	w.Flush()
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Validate checks for contradictory flags
+func (f CommonFlags) Validate() error {
+	if f.Checksum && f.IgnoreChecksum {
+		return &ValidationError{Field: "checksum", Message: "Checksum and IgnoreChecksum cannot both be set"}
+	}
+	if f.ChecksumAlgorithm != "" {
+		if !f.Checksum {
+			return &ValidationError{Field: "checksum_algorithm", Message: "requires Checksum"}
+		}
+		if !hashTypes[strings.ToLower(f.ChecksumAlgorithm)] {
+			return &ValidationError{
+				Field:   "checksum_algorithm",
+				Message: fmt.Sprintf("unknown hash type %q", f.ChecksumAlgorithm),
+			}
+		}
+	}
+	return nil
+}
+
+// TransferOption modifies an RcloneOptions value. Options are applied in
+// order, so later options override earlier ones.
+type TransferOption func(*RcloneOptions)
+
+// NewRcloneOptions returns RcloneOptions for a copy with the default stats
+// interval, then applies opts in order. It's the functional-option
+// counterpart of NewTransferOptions:
+//
+//	opts := NewRcloneOptions(
+//		Command(RcloneSync),
+//		Source("/data"),
+//		Destination("remote:backup"),
+//		Flags("--fast-list"),
+//	)
+func NewRcloneOptions(opts ...TransferOption) RcloneOptions {
+	result := RcloneOptions{
+		Command:       RcloneCopy,
+		Flags:         []string{},
+		StatsInterval: "500ms",
+	}
+	for _, opt := range opts {
+		opt(&result)
+	}
+	return result
+}
+
+// Command sets the rclone command
+func Command(cmd RcloneCommand) TransferOption {
+	return func(o *RcloneOptions) { o.Command = cmd }
+}
+
+// Source sets the source path
+func Source(path string) TransferOption {
+	return func(o *RcloneOptions) { o.Source = path }
+}
+
+// Destination sets the destination path
+func Destination(path string) TransferOption {
+	return func(o *RcloneOptions) { o.Destination = path }
+}
+
+// Flags adds custom flags
+func Flags(flags ...string) TransferOption {
+	return func(o *RcloneOptions) { o.Flags = append(o.Flags, flags...) }
+}
+
+// Common adds the flags for common, or with common.UseEnv merges its
+// environment variables into Env. Variables already in Env are kept.
+func Common(common CommonFlags) TransferOption {
+	if !common.UseEnv {
+		return Flags(common.ToFlags()...)
+	}
+	return func(o *RcloneOptions) {
+		env := common.ToEnvironment()
+		for k, v := range o.Env {
+			env[k] = v
+		}
+		o.Env = env
+	}
+}
+
+// StatsInterval sets the stats update interval
+func StatsInterval(interval time.Duration) TransferOption {
+	return func(o *RcloneOptions) { o.StatsInterval = interval.String() }
+}
+
+// DryRun enables dry-run mode
+func DryRun() TransferOption {
+	return func(o *RcloneOptions) { o.DryRun = true }
+}
+
+// Context sets the context used to cancel the transfer
+func Context(ctx context.Context) TransferOption {
+	return func(o *RcloneOptions) { o.Context = ctx }
+}
+
+// Env sets an environment variable for rclone
+func Env(key, value string) TransferOption {
+	return func(o *RcloneOptions) {
+		env := make(map[string]string, len(o.Env)+1)
+		for k, v := range o.Env {
+			env[k] = v
+		}
+		env[key] = value
+		o.Env = env
+	}
+}
+
+// PreExecute adds a check run before rclone is started
+func PreExecute(fn PreExecuteFunc) TransferOption {
+	return func(o *RcloneOptions) { o.PreExecute = append(o.PreExecute, fn) }
+}
+
+// Options combines several options into one, so common settings can be
+// bundled and reused
+func Options(opts ...TransferOption) TransferOption {
+	return func(o *RcloneOptions) {
+		for _, opt := range opts {
+			opt(o)
+		}
+	}
+}
+
 // TransferOptions provides a builder-pattern for configuring transfers
 type TransferOptions struct {
 	opts RcloneOptions
+
+	noCheckCertificate bool
+	allowInsecure      bool
+	autoMkdir          bool
+	serverSide         bool
+	multiThreadStreams int
+	cutoffTime         bool
+	autoRoute          *AutoRouteOptions
+	routeInfo          *SizeInfo
+
+	// errs collects problems found while building, reported by Validate
+	errs []error
 }
 
 // NewTransferOptions creates a new TransferOptions builder
 func NewTransferOptions(source, destination string) *TransferOptions {
 	return &TransferOptions{
-		opts: RcloneOptions{
-			Command:       RcloneCopy,
-			Source:        source,
-			Destination:   destination,
-			Flags:         []string{},
-			StatsInterval: "500ms",
-		},
+		opts: NewRcloneOptions(Source(source), Destination(destination)),
 	}
 }
 
+// With applies functional options to the builder, so options written for
+// NewRcloneOptions can be reused here
+func (t *TransferOptions) With(opts ...TransferOption) *TransferOptions {
+	for _, opt := range opts {
+		opt(&t.opts)
+	}
+	return t
+}
+
 // WithCommand sets the rclone command
 func (t *TransferOptions) WithCommand(cmd RcloneCommand) *TransferOptions {
 	t.opts.Command = cmd
@@ -101,10 +331,13 @@ func (t *TransferOptions) WithFlags(flags ...string) *TransferOptions {
 	return t
 }
 
-// WithCommonFlags adds common flags
+// WithCommonFlags adds common flags. Problems found by CommonFlags.Validate
+// are reported by Validate.
 func (t *TransferOptions) WithCommonFlags(common CommonFlags) *TransferOptions {
-	t.opts.Flags = append(t.opts.Flags, common.ToFlags()...)
-	return t
+	if err := common.Validate(); err != nil {
+		t.errs = append(t.errs, err)
+	}
+	return t.With(Common(common))
 }
 
 // WithStatsInterval sets the stats update interval
@@ -113,27 +346,434 @@ func (t *TransferOptions) WithStatsInterval(interval time.Duration) *TransferOpt
 	return t
 }
 
+// WithProgressTerminalTitle shows transfer progress in the terminal title
+// (--progress-terminal-title). It is dropped while the terminal UI is
+// running, since the UI owns the terminal.
+func (t *TransferOptions) WithProgressTerminalTitle() *TransferOptions {
+	t.opts.ProgressTerminalTitle = true
+	return t
+}
+
 // WithDryRun enables dry-run mode
 func (t *TransferOptions) WithDryRun() *TransferOptions {
 	t.opts.DryRun = true
 	return t
 }
 
-// Build returns the configured RcloneOptions
-func (t *TransferOptions) Build() RcloneOptions {
-	return t.opts
+// WithNoCheckDest skips checking the destination for existing files
+// (--no-check-dest). Everything is uploaded unconditionally, which is fastest
+// when the destination is known to be empty.
+func (t *TransferOptions) WithNoCheckDest() *TransferOptions {
+	t.opts.Flags = append(t.opts.Flags, "--no-check-dest")
+	return t
+}
+
+// WithNoUpdateModtime stops rclone updating the modification time of
+// destination files that are otherwise identical (--no-update-modtime), for
+// backends where that costs a request per file
+func (t *TransferOptions) WithNoUpdateModtime() *TransferOptions {
+	t.opts.Flags = append(t.opts.Flags, "--no-update-modtime")
+	return t
+}
+
+// WithNoGzip stops rclone asking servers for gzip-encoded responses
+// (--no-gzip-encoding), so files stored with Content-Encoding: gzip are
+// downloaded as stored
+func (t *TransferOptions) WithNoGzip() *TransferOptions {
+	t.opts.Flags = append(t.opts.Flags, "--no-gzip-encoding")
+	return t
+}
+
+// WithNoCheckCertificate disables TLS certificate verification
+// (--no-check-certificate). Validate rejects it unless WithAllowInsecure is
+// also used, so it can't be switched on by accident.
+func (t *TransferOptions) WithNoCheckCertificate() *TransferOptions {
+	t.opts.Flags = append(t.opts.Flags, "--no-check-certificate")
+	t.noCheckCertificate = true
+	return t
+}
+
+// WithAllowInsecure acknowledges the risk of WithNoCheckCertificate, e.g. for
+// internal servers with self-signed certificates
+func (t *TransferOptions) WithAllowInsecure() *TransferOptions {
+	t.allowInsecure = true
+	return t
+}
+
+// WithHTTP2 undoes WithDisableHTTP2. rclone uses HTTP/2 by default and has
+// no --http2 flag, so this only removes any --disable-http2 added earlier.
+func (t *TransferOptions) WithHTTP2() *TransferOptions {
+	flags := make([]string, 0, len(t.opts.Flags))
+	for _, f := range t.opts.Flags {
+		if f != "--disable-http2" {
+			flags = append(flags, f)
+		}
+	}
+	t.opts.Flags = flags
+	return t
+}
+
+// WithDisableHTTP2 disables HTTP/2 (--disable-http2)
+func (t *TransferOptions) WithDisableHTTP2() *TransferOptions {
+	t.opts.Flags = append(t.opts.Flags, "--disable-http2")
+	return t
 }
 
-// Helper to format int as string
-func formatInt(i int) string {
-	if i < 10 {
-		return string(rune(i + '0'))
+// WithCompareDest makes rclone compare against the reference directory path
+// as well as the destination and skip files found unchanged in either
+// (--compare-dest). Typically used with sync for incremental backups.
+func (t *TransferOptions) WithCompareDest(path string) *TransferOptions {
+	if path == "" {
+		t.errs = append(t.errs, &ValidationError{Field: "compare_dest", Message: "path cannot be empty"})
+		return t
+	}
+	t.opts.Flags = append(t.opts.Flags, "--compare-dest", path)
+	t.serverSide = true
+	return t
+}
+
+// WithCopyDest makes rclone server-side copy files found unchanged in the
+// reference directory path instead of uploading them again (--copy-dest).
+// Typically used with copy.
+func (t *TransferOptions) WithCopyDest(path string) *TransferOptions {
+	if path == "" {
+		t.errs = append(t.errs, &ValidationError{Field: "copy_dest", Message: "path cannot be empty"})
+		return t
+	}
+	t.opts.Flags = append(t.opts.Flags, "--copy-dest", path)
+	t.serverSide = true
+	return t
+}
+
+// HasServerSideOptimization reports whether WithCompareDest or WithCopyDest
+// has been used
+func (t *TransferOptions) HasServerSideOptimization() bool {
+	return t.serverSide
+}
+
+// WithMultiThreadStreams sets how many streams rclone uses to transfer a
+// single large file (--multi-thread-streams). 0 disables multi-thread
+// transfers.
+func (t *TransferOptions) WithMultiThreadStreams(n int) *TransferOptions {
+	if n < 0 {
+		t.errs = append(t.errs, &ValidationError{Field: "multi_thread_streams", Message: "cannot be negative"})
+		return t
+	}
+	t.opts.Flags = append(t.opts.Flags, "--multi-thread-streams", strconv.Itoa(n))
+	t.multiThreadStreams = n
+	return t
+}
+
+// WithMultiThreadCutoff sets the file size in bytes above which rclone uses
+// multi-thread transfers (--multi-thread-cutoff)
+func (t *TransferOptions) WithMultiThreadCutoff(size int64) *TransferOptions {
+	if size < 0 {
+		t.errs = append(t.errs, &ValidationError{Field: "multi_thread_cutoff", Message: "cannot be negative"})
+		return t
+	}
+	// A bare number would be read as KiB
+	t.opts.Flags = append(t.opts.Flags, "--multi-thread-cutoff", strconv.FormatInt(size, 10)+"B")
+	return t
+}
+
+// WithCompressionLevel sets the compression level used by compress remotes
+// (--compress-level), from 0 (fastest) to 9 (smallest)
+func (t *TransferOptions) WithCompressionLevel(level int) *TransferOptions {
+	if level < 0 || level > 9 {
+		t.errs = append(t.errs, &ValidationError{
+			Field:   "compress_level",
+			Message: fmt.Sprintf("level must be between 0 and 9, got %d", level),
+		})
+		return t
+	}
+	t.opts.Flags = append(t.opts.Flags, "--compress-level", strconv.Itoa(level))
+	return t
+}
+
+// WithCompressionMode sets the algorithm compress remotes use for new files
+// (--compress-mode): "gzip" or "zstd"
+func (t *TransferOptions) WithCompressionMode(mode string) *TransferOptions {
+	if mode != "gzip" && mode != "zstd" {
+		t.errs = append(t.errs, &ValidationError{
+			Field:   "compress_mode",
+			Message: fmt.Sprintf("unsupported mode %q (use gzip or zstd)", mode),
+		})
+		return t
+	}
+	t.opts.Flags = append(t.opts.Flags, "--compress-mode", mode)
+	return t
+}
+
+// WithDecompress makes S3 and GCS remotes decompress files stored with
+// Content-Encoding: gzip as they are downloaded (--s3-decompress,
+// --gcs-decompress). Other backends ignore it.
+func (t *TransferOptions) WithDecompress() *TransferOptions {
+	t.opts.Flags = append(t.opts.Flags, "--s3-decompress", "--gcs-decompress")
+	return t
+}
+
+// WithLocalNoCheckUpdated stops rclone failing files on a local source that
+// change while they are being copied (--local-no-check-updated), for
+// actively-written files such as database dumps and logs. The copy may not
+// match the final file. Warnings reports it if the source is a remote.
+func (t *TransferOptions) WithLocalNoCheckUpdated() *TransferOptions {
+	t.opts.Flags = append(t.opts.Flags, "--local-no-check-updated")
+	return t
+}
+
+// WithLocalNoSetModtime stops rclone setting the modification time of files
+// it writes to local disk (--local-no-set-modtime), for filesystems that
+// don't allow it. Warnings reports it if the destination is a remote.
+func (t *TransferOptions) WithLocalNoSetModtime() *TransferOptions {
+	t.opts.Flags = append(t.opts.Flags, "--local-no-set-modtime")
+	return t
+}
+
+// WithTempDir makes rclone put its temporary files in dir (--temp-dir),
+// e.g. a writable volume in a container with a read-only root filesystem.
+// Validate reports an error if dir isn't an existing, writable directory.
+func (t *TransferOptions) WithTempDir(dir string) *TransferOptions {
+	if err := checkWritableDir("temp_dir", dir); err != nil {
+		t.errs = append(t.errs, err)
+		return t
+	}
+	t.opts.Flags = append(t.opts.Flags, "--temp-dir", dir)
+	return t
+}
+
+// WithCacheDir sets the directory rclone keeps its caches in (--cache-dir),
+// including the VFS cache. Validate reports an error if dir isn't an
+// existing, writable directory.
+func (t *TransferOptions) WithCacheDir(dir string) *TransferOptions {
+	if err := checkWritableDir("cache_dir", dir); err != nil {
+		t.errs = append(t.errs, err)
+		return t
+	}
+	t.opts.Flags = append(t.opts.Flags, "--cache-dir", dir)
+	return t
+}
+
+// WithConfigDir uses the rclone.conf in dir. rclone has no --config-dir
+// flag, so this passes --config pointing at the file. Validate reports an
+// error if dir doesn't exist.
+func (t *TransferOptions) WithConfigDir(dir string) *TransferOptions {
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		t.errs = append(t.errs, &ValidationError{Field: "config_dir", Message: fmt.Sprintf("%q is not a directory", dir)})
+		return t
+	}
+	t.opts.Flags = append(t.opts.Flags, "--config", filepath.Join(dir, "rclone.conf"))
+	return t
+}
+
+// checkWritableDir returns a ValidationError unless dir is an existing
+// directory a file can be created in
+func checkWritableDir(field, dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return &ValidationError{Field: field, Message: fmt.Sprintf("%q is not a directory", dir)}
+	}
+	f, err := os.CreateTemp(dir, ".rclonelib-write-test-*")
+	if err != nil {
+		return &ValidationError{Field: field, Message: fmt.Sprintf("%q is not writable", dir)}
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return nil
+}
+
+// CutoffMode controls how rclone stops when it reaches a limit such as
+// --max-duration or --max-transfer (--cutoff-mode)
+type CutoffMode string
+
+const (
+	// CutoffHard stops transferring immediately
+	CutoffHard CutoffMode = "hard"
+	// CutoffSoft stops starting new transfers but finishes running ones
+	CutoffSoft CutoffMode = "soft"
+	// CutoffCautious doesn't start transfers that would exceed the limit
+	CutoffCautious CutoffMode = "cautious"
+)
+
+// WithCutoffTime only transfers files last modified before cutoff, so a
+// sync of a directory that is still being written produces a consistent
+// snapshot. Validate requires the sync or bisync command.
+func (t *TransferOptions) WithCutoffTime(cutoff time.Time) *TransferOptions {
+	// --min-age accepts an absolute time as well as a duration
+	t.opts.Flags = append(t.opts.Flags, "--min-age", cutoff.Format(time.RFC3339))
+	t.cutoffTime = true
+	return t
+}
+
+// WithCutoffMode sets how rclone stops when it reaches --max-duration or
+// --max-transfer
+func (t *TransferOptions) WithCutoffMode(mode CutoffMode) *TransferOptions {
+	switch mode {
+	case CutoffHard, CutoffSoft, CutoffCautious:
+	default:
+		t.errs = append(t.errs, &ValidationError{
+			Field:   "cutoff_mode",
+			Message: fmt.Sprintf("unknown cutoff mode %q (use hard, soft or cautious)", mode),
+		})
+		return t
+	}
+	t.opts.Flags = append(t.opts.Flags, "--cutoff-mode", string(mode))
+	return t
+}
+
+// MetadataOptions configures metadata handling for WithMetadata
+type MetadataOptions struct {
+	// Preserve copies metadata from source to destination (--metadata)
+	Preserve bool
+	// Set adds or overrides metadata on every file written
+	// (--metadata-set key=value)
+	Set map[string]string
+}
+
+// WithMetadata configures metadata handling, for backends such as S3 and GCS
+// that store custom metadata alongside files
+func (t *TransferOptions) WithMetadata(opts MetadataOptions) *TransferOptions {
+	if opts.Preserve {
+		t.opts.Flags = append(t.opts.Flags, "--metadata")
+	}
+
+	keys := make([]string, 0, len(opts.Set))
+	for key := range opts.Set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := opts.Set[key]
+		if key == "" {
+			t.errs = append(t.errs, &ValidationError{Field: "metadata", Message: "key cannot be empty"})
+			continue
+		}
+		if strings.Contains(value, "=") {
+			t.errs = append(t.errs, &ValidationError{Field: "metadata", Message: fmt.Sprintf("value for %q cannot contain '='", key)})
+			continue
+		}
+		t.opts.Flags = append(t.opts.Flags, "--metadata-set", key+"="+value)
+	}
+	return t
+}
+
+// WithMkdirDst avoids listing the destination before transferring
+// (--no-traverse), for copying into a directory that may not exist yet.
+// Combine with WithAutoMkdir to create it first.
+func (t *TransferOptions) WithMkdirDst() *TransferOptions {
+	t.opts.Flags = append(t.opts.Flags, "--no-traverse")
+	return t
+}
+
+// WithAutoMkdir makes Execute create the destination's parent directory,
+// via EnsureDestinationDir, before starting the transfer
+func (t *TransferOptions) WithAutoMkdir(enabled bool) *TransferOptions {
+	t.autoMkdir = enabled
+	return t
+}
+
+// WithPreExecuteCheck adds a check that Execute runs before starting rclone.
+// Checks run in the order they were added; if one returns an error the
+// transfer fails with it and rclone is never started.
+func (t *TransferOptions) WithPreExecuteCheck(fn PreExecuteFunc) *TransferOptions {
+	t.opts.PreExecute = append(t.opts.PreExecute, fn)
+	return t
+}
+
+// WithMinFreeSpace fails the transfer before it starts unless a local
+// destination has at least bytes of free space, using CheckDiskSpaceContext.
+// It has no effect on remote destinations; see WithPreExecuteCheckRemote.
+func (t *TransferOptions) WithMinFreeSpace(bytes int64) *TransferOptions {
+	return t.WithPreExecuteCheck(func(ctx context.Context, opts RcloneOptions) error {
+		if IsRemotePath(opts.Destination) {
+			return nil
+		}
+		return CheckDiskSpaceContext(ctx, opts.Destination, bytes)
+	})
+}
+
+// WithPreExecuteCheckRemote fails the transfer before it starts unless a
+// remote destination reports at least bytes free via GetRemoteQuota. Remotes
+// that don't report free space are let through, as are local destinations.
+func (t *TransferOptions) WithPreExecuteCheckRemote(bytes int64) *TransferOptions {
+	return t.WithPreExecuteCheck(func(ctx context.Context, opts RcloneOptions) error {
+		if !IsRemotePath(opts.Destination) {
+			return nil
+		}
+		return CheckDiskSpaceContext(ctx, opts.Destination, bytes)
+	})
+}
+
+// Validate checks the configured options for unsafe or inconsistent
+// combinations
+func (t *TransferOptions) Validate() error {
+	if len(t.errs) > 0 {
+		return t.errs[0]
+	}
+	if err := ValidateStatsInterval(t.opts.StatsInterval); err != nil {
+		return err
+	}
+	if t.multiThreadStreams > 1 && hasFlag(t.opts.Flags, "--s3-upload-concurrency") {
+		return &ValidationError{
+			Field:   "flags",
+			Message: "--multi-thread-streams conflicts with --s3-upload-concurrency; use one or the other",
+		}
+	}
+	if t.cutoffTime && t.opts.Command != RcloneSync && t.opts.Command != RcloneBisync {
+		return &ValidationError{
+			Field:   "command",
+			Message: fmt.Sprintf("WithCutoffTime requires sync or bisync, not %s", t.opts.Command),
+		}
+	}
+	if t.noCheckCertificate && !t.allowInsecure {
+		return &ValidationError{
+			Field:   "flags",
+			Message: "--no-check-certificate requires WithAllowInsecure",
+		}
+	}
+	return nil
+}
+
+// Warnings returns advisory messages about option combinations that are
+// allowed but probably unintended. Unlike Validate these never block a
+// transfer. Pass the manager the transfer will run under, or nil.
+func (t *TransferOptions) Warnings(m *Manager) []string {
+	var warnings []string
+
+	if m != nil && m.tuiAttached() {
+		progress := hasFlag(t.opts.Flags, "-P") || hasFlag(t.opts.Flags, "--progress")
+		title := t.opts.ProgressTerminalTitle || hasFlag(t.opts.Flags, "--progress-terminal-title")
+		if progress {
+			warnings = append(warnings, "-P output conflicts with the terminal UI; progress is already shown there")
+		}
+		if title {
+			warnings = append(warnings, "--progress-terminal-title is ignored while the terminal UI is running")
+		}
+	}
+
+	// The local backend's flags only affect the local side of a transfer
+	if IsRemotePath(t.opts.Source) && hasFlag(t.opts.Flags, "--local-no-check-updated") {
+		warnings = append(warnings, "--local-no-check-updated has no effect on a remote source")
+	}
+	if IsRemotePath(t.opts.Destination) && hasFlag(t.opts.Flags, "--local-no-set-modtime") {
+		warnings = append(warnings, "--local-no-set-modtime has no effect on a remote destination")
+	}
+
+	return warnings
+}
+
+// Build returns the configured RcloneOptions. With WithAutoRoute this sizes
+// the source, which runs rclone the first time.
+func (t *TransferOptions) Build() RcloneOptions {
+	opts := t.opts
+	if t.autoRoute != nil {
+		opts = t.applyAutoRoute(opts)
 	}
-	// For larger numbers, use proper conversion
-	var result []byte
-	for i > 0 {
-		result = append([]byte{byte(i%10 + '0')}, result...)
-		i /= 10
+	if t.autoMkdir {
+		hooks := make([]PreExecuteFunc, 0, len(opts.PreExecute)+1)
+		hooks = append(hooks, ensureDestinationParent)
+		opts.PreExecute = append(hooks, opts.PreExecute...)
 	}
-	return string(result)
+	return opts
 }
@@ -0,0 +1,212 @@
+package rclonelib
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCommonFlagsToFlags_Numbers(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{1, "1"},
+		{2, "2"},
+		{8, "8"},
+		{9, "9"},
+		{10, "10"},
+		{99, "99"},
+		{100, "100"},
+		{1000, "1000"},
+		{65535, "65535"},
+	}
+
+	for _, tt := range tests {
+		flags := CommonFlags{Transfers: tt.n, Checkers: tt.n, Bandwidth: tt.n}.ToFlags()
+		want := []string{"--transfers", tt.want, "--checkers", tt.want, "--bwlimit", tt.want + "k"}
+		if !reflect.DeepEqual(flags, want) {
+			t.Errorf("ToFlags(%d) = %v, want %v", tt.n, flags, want)
+		}
+	}
+}
+
+func TestCommonFlagsToFlags_ZeroOmitted(t *testing.T) {
+	if flags := (CommonFlags{}).ToFlags(); len(flags) != 0 {
+		t.Errorf("expected no flags for zero values, got %v", flags)
+	}
+}
+
+func TestPreExecuteChecks_RunInOrderAndStopOnError(t *testing.T) {
+	errFull := errors.New("destination full")
+	var ran []string
+
+	opts := NewTransferOptions(t.TempDir(), "remote:dst").
+		WithPreExecuteCheck(func(ctx context.Context, opts RcloneOptions) error {
+			ran = append(ran, "first")
+			return nil
+		}).
+		WithPreExecuteCheck(func(ctx context.Context, opts RcloneOptions) error {
+			ran = append(ran, "second")
+			return errFull
+		}).
+		WithPreExecuteCheck(func(ctx context.Context, opts RcloneOptions) error {
+			ran = append(ran, "third")
+			return nil
+		}).
+		Build()
+
+	m := NewManager()
+	m.Add("t1", opts.Source, opts.Destination)
+	err := NewExecutor(m).Execute("t1", opts)
+	if !errors.Is(err, errFull) {
+		t.Fatalf("expected pre-check error, got %v", err)
+	}
+	if !reflect.DeepEqual(ran, []string{"first", "second"}) {
+		t.Errorf("checks ran = %v, want [first second]", ran)
+	}
+}
+
+func TestNewRcloneOptions(t *testing.T) {
+	base := Options(Command(RcloneSync), Flags("--fast-list"))
+	opts := NewRcloneOptions(base, Source("/data"), Destination("remote:backup"), Flags("-v"), Env("RCLONE_X", "1"))
+
+	if opts.Command != RcloneSync {
+		t.Errorf("Command = %q, want sync", opts.Command)
+	}
+	if opts.Source != "/data" || opts.Destination != "remote:backup" {
+		t.Errorf("paths = %q -> %q", opts.Source, opts.Destination)
+	}
+	if !reflect.DeepEqual(opts.Flags, []string{"--fast-list", "-v"}) {
+		t.Errorf("Flags = %v", opts.Flags)
+	}
+	if opts.StatsInterval != "500ms" {
+		t.Errorf("StatsInterval = %q, want default 500ms", opts.StatsInterval)
+	}
+	if opts.Env["RCLONE_X"] != "1" {
+		t.Errorf("Env = %v", opts.Env)
+	}
+}
+
+func TestCommonFlagsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   CommonFlags
+		wantErr bool
+	}{
+		{"none", CommonFlags{}, false},
+		{"checksum", CommonFlags{Checksum: true}, false},
+		{"algorithm with checksum", CommonFlags{Checksum: true, ChecksumAlgorithm: "SHA1"}, false},
+		{"algorithm without checksum", CommonFlags{ChecksumAlgorithm: "md5"}, true},
+		{"unknown algorithm", CommonFlags{Checksum: true, ChecksumAlgorithm: "rot13"}, true},
+		{"checksum and ignore", CommonFlags{Checksum: true, IgnoreChecksum: true}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.flags.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLocalBackendOptionWarnings(t *testing.T) {
+	local := NewTransferOptions("/var/backups/db.sql", "/mnt/archive").
+		WithLocalNoCheckUpdated().
+		WithLocalNoSetModtime()
+	if w := local.Warnings(nil); len(w) != 0 {
+		t.Errorf("local transfer warnings = %v, want none", w)
+	}
+	if err := local.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+
+	remote := NewTransferOptions("s3:bucket/db.sql", "b2:archive").
+		WithLocalNoCheckUpdated().
+		WithLocalNoSetModtime()
+	if w := remote.Warnings(nil); len(w) != 2 {
+		t.Errorf("remote transfer warnings = %v, want 2", w)
+	}
+}
+
+func TestCommonFlagsUseEnv(t *testing.T) {
+	common := CommonFlags{Transfers: 8, Checksum: true, Exclude: []string{"*.tmp", "a,b"}, UseEnv: true}
+
+	env := common.ToEnvironment()
+	if env["RCLONE_TRANSFERS"] != "8" || env["RCLONE_CHECKSUM"] != "true" {
+		t.Errorf("ToEnvironment() = %v", env)
+	}
+	if env["RCLONE_EXCLUDE"] != `*.tmp,"a,b"` {
+		t.Errorf("RCLONE_EXCLUDE = %q", env["RCLONE_EXCLUDE"])
+	}
+
+	opts := NewRcloneOptions(Env("RCLONE_TRANSFERS", "2"), Common(common))
+	if len(opts.Flags) != 0 {
+		t.Errorf("Flags = %v, want none with UseEnv", opts.Flags)
+	}
+	if opts.Env["RCLONE_TRANSFERS"] != "2" || opts.Env["RCLONE_CHECKSUM"] != "true" {
+		t.Errorf("Env = %v, want explicit RCLONE_TRANSFERS kept and the rest merged", opts.Env)
+	}
+}
+
+func TestWithAutoRoute(t *testing.T) {
+	route := AutoRouteOptions{SingleFileCutoff: 1 << 20, MultiPartCutoff: 1 << 30}
+	tests := []struct {
+		name      string
+		info      SizeInfo
+		wantCmd   RcloneCommand
+		wantDst   string
+		wantFlags bool
+	}{
+		{"small file", SizeInfo{Size: 1024, Count: 1}, RcloneCopyTo, "s3:bucket/backups/report.pdf", false},
+		{"medium file", SizeInfo{Size: 100 << 20, Count: 1}, RcloneCopy, "s3:bucket/backups", false},
+		{"large file", SizeInfo{Size: 2 << 30, Count: 1}, RcloneCopy, "s3:bucket/backups", true},
+		{"directory", SizeInfo{Size: 10, Count: 3, IsDir: true}, RcloneCopy, "s3:bucket/backups", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			to := NewTransferOptions("/data/report.pdf", "s3:bucket/backups").WithAutoRoute(route)
+			info := tt.info
+			to.routeInfo = &info // skip the rclone lookup
+
+			opts := to.Build()
+			if opts.Command != tt.wantCmd || opts.Destination != tt.wantDst {
+				t.Errorf("Build() = %s to %s, want %s to %s", opts.Command, opts.Destination, tt.wantCmd, tt.wantDst)
+			}
+			if got := hasFlag(opts.Flags, "--multi-thread-cutoff"); got != tt.wantFlags {
+				t.Errorf("--multi-thread-cutoff set = %v, want %v", got, tt.wantFlags)
+			}
+		})
+	}
+}
+
+func TestWithTempDir(t *testing.T) {
+	dir := t.TempDir()
+	opts := NewTransferOptions("/src", "remote:dst").WithTempDir(dir).WithCacheDir(dir)
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	if flags := opts.Build().Flags; !hasFlag(flags, "--temp-dir") || !hasFlag(flags, "--cache-dir") {
+		t.Errorf("Flags = %v", flags)
+	}
+
+	missing := NewTransferOptions("/src", "remote:dst").WithTempDir(filepath.Join(dir, "missing"))
+	if err := missing.Validate(); err == nil {
+		t.Error("expected an error for a missing temp dir")
+	}
+}
+
+func TestWithHTTP2(t *testing.T) {
+	opts := NewTransferOptions("/src", "remote:dst").WithDisableHTTP2().WithNoCheckDest().WithHTTP2().Build()
+	if hasFlag(opts.Flags, "--disable-http2") || hasFlag(opts.Flags, "--http2") {
+		t.Errorf("flags = %v, want neither --disable-http2 nor --http2", opts.Flags)
+	}
+	if !hasFlag(opts.Flags, "--no-check-dest") {
+		t.Errorf("flags = %v, lost --no-check-dest", opts.Flags)
+	}
+}
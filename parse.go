@@ -0,0 +1,171 @@
+package rclonelib
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProgressUpdate is the progress reported by one rclone stats line
+type ProgressUpdate struct {
+	Percent     float64
+	BytesCopied int64
+	BytesTotal  int64
+	// Speed is the transfer rate in bytes per second, or 0 if the line
+	// didn't include one
+	Speed float64
+	// ETA is rclone's estimate of the time remaining, or 0 if unknown
+	ETA time.Duration
+}
+
+// AutoParser recognises rclone's stats in any of the formats it can print
+// them in: the "Transferred:" block logged with -v, the -P progress display,
+// --use-json-log and --stats-one-line. Parsers are tried in priority order
+// and the first match wins.
+type AutoParser struct{}
+
+// DefaultParser is the AutoParser used by ParseTransferOutput
+var DefaultParser = &AutoParser{}
+
+// progressParsers are AutoParser's strategies in priority order
+var progressParsers = []func(line string) (*ProgressUpdate, bool){
+	parseJSONLogStats,
+	parseVerboseStats,
+	parseProgressStats,
+	parseOneLineStats,
+}
+
+// Parse returns the progress reported by line, or false if it isn't a stats
+// line
+func (p *AutoParser) Parse(line string) (*ProgressUpdate, bool) {
+	for _, parse := range progressParsers {
+		if update, ok := parse(line); ok {
+			return update, true
+		}
+	}
+	return nil, false
+}
+
+// ParseTransferOutput returns the progress reported by a line of rclone
+// output in any known stats format, or false if it isn't a stats line. It
+// has no side effects, so it can be used to build custom output processors.
+func ParseTransferOutput(line string) (*ProgressUpdate, bool) {
+	return DefaultParser.Parse(line)
+}
+
+// statsPattern matches the body of a stats line:
+// "1.234 GiB / 5.678 GiB, 22%, 10 MiB/s, ETA 1m30s". rclone prints "-" for
+// the percentage and ETA when they're unknown.
+const statsPattern = `([0-9.]+)\s*([kKMGTP]?i?B)\s*/\s*([0-9.]+)\s*([kKMGTP]?i?B),\s*(?:([0-9]+)%|-)` +
+	`(?:,\s*([0-9.]+)\s*([kKMGTP]?i?B)/s)?(?:,\s*ETA\s+(\S+))?`
+
+var (
+	// verboseStatsRegex matches the "Transferred:" line of the stats block
+	verboseStatsRegex = regexp.MustCompile(`Transferred:\s+` + statsPattern)
+	// oneLineStatsRegex matches --stats-one-line output, optionally after a
+	// log prefix or the --stats-one-line-date timestamp
+	oneLineStatsRegex = regexp.MustCompile(`^(?:\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}(?:\.\d+)?\s+(?:[A-Z]+\s*:|-)?)?\s*` + statsPattern)
+	// ansiRegex matches the terminal escape sequences -P redraws with
+	ansiRegex = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+)
+
+// parseVerboseStats parses the "Transferred:" line rclone logs with -v
+func parseVerboseStats(line string) (*ProgressUpdate, bool) {
+	return statsFromMatches(verboseStatsRegex.FindStringSubmatch(line))
+}
+
+// parseProgressStats parses the -P display, which is the verbose format
+// wrapped in terminal escape sequences
+func parseProgressStats(line string) (*ProgressUpdate, bool) {
+	if !strings.Contains(line, "\x1b") {
+		return nil, false
+	}
+	return parseVerboseStats(ansiRegex.ReplaceAllString(line, ""))
+}
+
+// parseOneLineStats parses --stats-one-line output
+func parseOneLineStats(line string) (*ProgressUpdate, bool) {
+	return statsFromMatches(oneLineStatsRegex.FindStringSubmatch(line))
+}
+
+// statsFromMatches builds an update from a statsPattern match, whose groups
+// are the last eight of matches
+func statsFromMatches(matches []string) (*ProgressUpdate, bool) {
+	if len(matches) < 9 {
+		return nil, false
+	}
+	m := matches[len(matches)-8:]
+
+	update := &ProgressUpdate{
+		BytesCopied: parseSize(m[0], m[1]),
+		BytesTotal:  parseSize(m[2], m[3]),
+	}
+	if m[4] != "" {
+		update.Percent, _ = strconv.ParseFloat(m[4], 64)
+	}
+	if m[5] != "" {
+		update.Speed = float64(parseSize(m[5], m[6]))
+	}
+	update.ETA = parseETA(m[7])
+	return update, true
+}
+
+// parseETA parses rclone's ETA, which may use days ("1d2h3m4s") and is "-"
+// when unknown
+func parseETA(s string) time.Duration {
+	var days time.Duration
+	if i := strings.Index(s, "d"); i > 0 {
+		n, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return 0
+		}
+		days, s = time.Duration(n)*24*time.Hour, s[i+1:]
+		if s == "" {
+			return days
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0
+	}
+	return days + d
+}
+
+// jsonLogStats is the part of a --use-json-log stats entry used for progress
+type jsonLogStats struct {
+	Stats *struct {
+		Bytes      int64    `json:"bytes"`
+		TotalBytes int64    `json:"totalBytes"`
+		Speed      float64  `json:"speed"`
+		ETA        *float64 `json:"eta"`
+	} `json:"stats"`
+}
+
+// parseJSONLogStats parses the stats entry rclone logs with --use-json-log
+func parseJSONLogStats(line string) (*ProgressUpdate, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "{") || !strings.Contains(line, `"stats"`) {
+		return nil, false
+	}
+
+	var entry jsonLogStats
+	if err := json.Unmarshal([]byte(line), &entry); err != nil || entry.Stats == nil {
+		return nil, false
+	}
+
+	s := entry.Stats
+	update := &ProgressUpdate{
+		BytesCopied: s.Bytes,
+		BytesTotal:  s.TotalBytes,
+		Speed:       s.Speed,
+	}
+	if s.TotalBytes > 0 {
+		update.Percent = float64(s.Bytes) / float64(s.TotalBytes) * 100
+	}
+	if s.ETA != nil {
+		update.ETA = time.Duration(*s.ETA * float64(time.Second))
+	}
+	return update, true
+}
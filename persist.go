@@ -0,0 +1,201 @@
+package rclonelib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// persistedState is the top-level document written to the state file. Each
+// transfer is stored in the format written by Transfer.JSON.
+type persistedState struct {
+	Transfers []Transfer `json:"transfers"`
+}
+
+// PersistentManager is a Manager that writes a snapshot of its transfers to a
+// state file whenever one is added or changes status, annotations or any
+// other persisted field, so a batch can be picked up again after the process
+// restarts. Changes are picked up through a notifier on the Manager, so they
+// are saved however they are made: directly, or by an Executor, Pool or
+// Drain working on the embedded Manager.
+//
+// Progress updates are not persisted on their own (they arrive several times
+// a second); the latest progress is included in the next snapshot written.
+// Neither are the options recorded for Replay, so after a restart Options
+// has nothing for restored transfers. Pass the embedded Manager to NewExecutor and NewModel as usual.
+type PersistentManager struct {
+	*Manager
+
+	stateFile string
+
+	saveMu  sync.Mutex
+	lastErr error
+
+	// savedMu guards saved, the persisted form of each transfer as of the
+	// last change that was saved, minus progress
+	savedMu sync.Mutex
+	saved   map[string]string
+}
+
+// NewPersistentManager creates a PersistentManager backed by stateFile. If the
+// file exists its transfers are restored; transfers that were pending or in
+// progress when the state was written come back as StatusPending so they can
+// be executed again. If the file doesn't exist it is created.
+func NewPersistentManager(stateFile string, opts ...ManagerOption) (*PersistentManager, error) {
+	if stateFile == "" {
+		return nil, &ValidationError{Field: "stateFile", Message: "state file path cannot be empty"}
+	}
+
+	pm := &PersistentManager{
+		Manager:   NewManager(opts...),
+		stateFile: stateFile,
+		saved:     make(map[string]string),
+	}
+
+	data, err := os.ReadFile(stateFile)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	case len(data) > 0:
+		var state persistedState
+		if err := json.Unmarshal(data, &state); err != nil {
+			return nil, fmt.Errorf("failed to parse state file: %w", err)
+		}
+		pm.restore(state)
+	}
+
+	// Write straight away so the file exists and shows interrupted transfers
+	// as pending
+	if err := pm.Flush(); err != nil {
+		return nil, err
+	}
+	pm.Manager.AddNotifier(NotifierFunc(pm.changed))
+
+	return pm, nil
+}
+
+// restore loads persisted transfers into the embedded Manager
+func (pm *PersistentManager) restore(state persistedState) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	for i := range state.Transfers {
+		t := &state.Transfers[i]
+
+		// Anything that hadn't finished is queued again from scratch.
+		if t.Status == StatusPending || t.Status == StatusInProgress {
+			t.Status = StatusPending
+			t.reset()
+		}
+
+		if _, exists := pm.transfers[t.ID]; !exists {
+			pm.order = append(pm.order, t.ID)
+		}
+		pm.transfers[t.ID] = t
+	}
+}
+
+// changed is the notifier that persists changes to t. Updates that only
+// move progress along are skipped.
+func (pm *PersistentManager) changed(t Transfer) {
+	t.Progress, t.BytesCopied, t.BytesTotal = 0, 0, 0
+	key, err := json.Marshal(t)
+	if err != nil {
+		return
+	}
+
+	pm.savedMu.Lock()
+	if pm.saved[t.ID] == string(key) {
+		pm.savedMu.Unlock()
+		return
+	}
+	pm.saved[t.ID] = string(key)
+	pm.savedMu.Unlock()
+
+	pm.save()
+}
+
+// Flush writes the current state to the state file. It also returns any
+// error from an earlier automatic write that hasn't been reported yet.
+func (pm *PersistentManager) Flush() error {
+	pm.saveMu.Lock()
+	defer pm.saveMu.Unlock()
+
+	prevErr := pm.lastErr
+	pm.lastErr = nil
+
+	if err := pm.writeLocked(); err != nil {
+		return err
+	}
+	return prevErr
+}
+
+// save writes the current state, remembering any error for the next Flush
+func (pm *PersistentManager) save() {
+	pm.saveMu.Lock()
+	defer pm.saveMu.Unlock()
+
+	if err := pm.writeLocked(); err != nil {
+		pm.lastErr = err
+	}
+}
+
+// writeLocked snapshots the manager and atomically replaces the state file.
+// The caller must hold saveMu.
+func (pm *PersistentManager) writeLocked() error {
+	data, err := json.MarshalIndent(pm.snapshot(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	// Write to a temp file in the same directory and rename over the state
+	// file so a crash mid-write never leaves a truncated snapshot behind.
+	dir := filepath.Dir(pm.stateFile)
+	tmp, err := os.CreateTemp(dir, filepath.Base(pm.stateFile)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp state file: %w", err)
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to sync state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to close state file: %w", err)
+	}
+	if err := os.Rename(tmpName, pm.stateFile); err != nil {
+		os.Remove(tmpName)
+		return fmt.Errorf("failed to replace state file: %w", err)
+	}
+
+	return nil
+}
+
+// snapshot copies the manager's transfers under the read lock
+func (pm *PersistentManager) snapshot() persistedState {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	state := persistedState{Transfers: make([]Transfer, 0, len(pm.order))}
+	for _, id := range pm.order {
+		t, exists := pm.transfers[id]
+		if !exists {
+			continue
+		}
+		// Copied so encoding doesn't race with later updates
+		state.Transfers = append(state.Transfers, *t)
+	}
+	return state
+}
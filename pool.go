@@ -0,0 +1,157 @@
+package rclonelib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Pool runs submitted transfers with at most MaxConcurrent executing at
+// once; the rest wait in submission order. Each transfer is marked started
+// when it gets a slot, then completed or failed, so a Model shows accurate
+// counts. A failure doesn't affect the other transfers.
+type Pool struct {
+	ctx      context.Context
+	manager  *Manager
+	executor *Executor
+	max      int
+
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	closed  bool
+	running int
+	queue   []poolJob
+	order   []string
+	errs    map[string]error
+	skipped int
+}
+
+type poolJob struct {
+	id       string
+	opts     RcloneOptions
+	retryCfg RetryConfig
+}
+
+// NewPool returns a pool running transfers of manager with executor, at
+// most maxConcurrent at a time (minimum 1). Cancelling ctx stops further
+// transfers from starting; those already running are left to finish, as
+// they use their own RcloneOptions.Context.
+func NewPool(ctx context.Context, manager *Manager, executor *Executor, maxConcurrent int) *Pool {
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+	return &Pool{
+		ctx:      ctx,
+		manager:  manager,
+		executor: executor,
+		max:      maxConcurrent,
+		errs:     make(map[string]error),
+	}
+}
+
+// Submit queues the transfer id, which must already have been added to the
+// manager, to run with ExecuteWithRetry once a slot is free. It returns
+// ErrPoolClosed once Wait has been called.
+func (p *Pool) Submit(id string, opts RcloneOptions, retryCfg RetryConfig) error {
+	if _, exists := p.manager.Get(id); !exists {
+		return fmt.Errorf("%w: %s", ErrTransferNotFound, id)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrPoolClosed
+	}
+	if p.ctx.Err() != nil {
+		p.skipped++
+		return nil
+	}
+
+	p.order = append(p.order, id)
+	p.wg.Add(1)
+	job := poolJob{id: id, opts: opts, retryCfg: retryCfg}
+	if p.running < p.max {
+		p.running++
+		go p.run(job)
+	} else {
+		p.queue = append(p.queue, job)
+	}
+	return nil
+}
+
+// Wait closes the pool to new submissions and blocks until every submitted
+// transfer has finished. It returns the failures joined together as
+// TransferErrors in submission order, plus the context's error if
+// cancellation stopped any transfer from starting. Transfers that never
+// started are left pending in the manager.
+func (p *Pool) Wait() error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+
+	p.wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var errs []error
+	for _, id := range p.order {
+		if err, failed := p.errs[id]; failed {
+			errs = append(errs, TransferError{ID: id, Err: err})
+		}
+	}
+	if p.skipped > 0 {
+		errs = append(errs, fmt.Errorf("%d transfers not started: %w", p.skipped, p.ctx.Err()))
+	}
+	return errors.Join(errs...)
+}
+
+// run executes job, then keeps taking queued jobs until the queue is empty
+// or the context is cancelled
+func (p *Pool) run(job poolJob) {
+	for {
+		p.execute(job)
+
+		p.mu.Lock()
+		if p.ctx.Err() != nil {
+			// Drop everything still queued
+			p.skipped += len(p.queue)
+			for range p.queue {
+				p.wg.Done()
+			}
+			p.queue = nil
+		}
+		if len(p.queue) == 0 {
+			p.running--
+			p.mu.Unlock()
+			return
+		}
+		job = p.queue[0]
+		p.queue = p.queue[1:]
+		p.mu.Unlock()
+	}
+}
+
+// execute runs one transfer and records how it ended
+func (p *Pool) execute(job poolJob) {
+	defer p.wg.Done()
+
+	if err := p.manager.Start(job.id); err != nil {
+		p.recordErr(job.id, err)
+		return
+	}
+	if err := p.executor.ExecuteWithRetry(job.id, job.opts, job.retryCfg); err != nil {
+		p.manager.Fail(job.id, err)
+		p.recordErr(job.id, err)
+		return
+	}
+	p.manager.Complete(job.id)
+}
+
+func (p *Pool) recordErr(id string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.errs[id] = err
+}
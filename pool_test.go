@@ -0,0 +1,80 @@
+package rclonelib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPool_LimitsConcurrency(t *testing.T) {
+	m := NewManager()
+	pool := NewPool(context.Background(), m, NewExecutor(m), 2)
+
+	var running, peak atomic.Int32
+	errStop := errors.New("stop before rclone")
+	hook := func(ctx context.Context, opts RcloneOptions) error {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		running.Add(-1)
+		return errStop
+	}
+
+	for i := 0; i < 5; i++ {
+		id := fmt.Sprintf("t%d", i)
+		m.Add(id, "/src", "remote:dst")
+		opts := NewRcloneOptions(Source("/src"), Destination("remote:dst"), PreExecute(hook))
+		if err := pool.Submit(id, opts, RetryConfig{MaxAttempts: 1}); err != nil {
+			t.Fatalf("Submit(%s): %v", id, err)
+		}
+	}
+
+	err := pool.Wait()
+	if !errors.Is(err, errStop) {
+		t.Fatalf("Wait() = %v, want the transfers' errors", err)
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("%d transfers ran at once, want at most 2", p)
+	}
+	if _, _, _, failed := m.Stats(); failed != 5 {
+		t.Errorf("%d transfers failed, want all 5 to have run", failed)
+	}
+
+	m.Add("late", "/src", "remote:dst")
+	if err := pool.Submit("late", RcloneOptions{}, RetryConfig{}); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("Submit after Wait = %v, want ErrPoolClosed", err)
+	}
+}
+
+func TestPool_CancelStopsDispatch(t *testing.T) {
+	m := NewManager()
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := NewPool(ctx, m, NewExecutor(m), 0)
+
+	hook := func(context.Context, RcloneOptions) error {
+		cancel()
+		return errors.New("stop before rclone")
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		m.Add(id, "/src", "remote:dst")
+		opts := NewRcloneOptions(PreExecute(hook))
+		if err := pool.Submit(id, opts, RetryConfig{MaxAttempts: 1}); err != nil {
+			t.Fatalf("Submit(%s): %v", id, err)
+		}
+	}
+
+	if err := pool.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait() = %v, want context.Canceled", err)
+	}
+	if pending, _, _, failed := m.Stats(); failed != 1 || pending != 2 {
+		t.Errorf("pending %d, failed %d; want only the first transfer run", pending, failed)
+	}
+}
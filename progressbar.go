@@ -0,0 +1,59 @@
+package rclonelib
+
+import (
+	"math"
+	"strings"
+)
+
+// ProgressBarStyle controls how RenderProgressBar draws a bar
+type ProgressBarStyle struct {
+	Fill  rune
+	Empty rune
+	// Partial, if set, marks a cell that is partly complete
+	Partial      rune
+	LeftBracket  string
+	RightBracket string
+}
+
+// DefaultProgressBarStyle draws bars like "[=====     ]"
+func DefaultProgressBarStyle() ProgressBarStyle {
+	return ProgressBarStyle{Fill: '=', Empty: ' ', LeftBracket: "[", RightBracket: "]"}
+}
+
+// BlockProgressBarStyle draws bars from Unicode block characters, like
+// "█████▓░░░░"
+func BlockProgressBarStyle() ProgressBarStyle {
+	return ProgressBarStyle{Fill: '█', Empty: '░', Partial: '▓'}
+}
+
+// RenderProgressBar draws a plain-text progress bar width cells wide (not
+// counting brackets) for percent (0-100), for output where the Bubble Tea UI
+// isn't available, such as logs and CI. Out-of-range percentages are
+// clamped.
+func RenderProgressBar(percent float64, width int, style ProgressBarStyle) string {
+	if width < 0 {
+		width = 0
+	}
+	if math.IsNaN(percent) || percent < 0 {
+		percent = 0
+	}
+	if percent > 100 {
+		percent = 100
+	}
+
+	cells := percent / 100 * float64(width)
+	full := int(cells)
+	partial := style.Partial != 0 && full < width && cells > float64(full)
+
+	var b strings.Builder
+	b.WriteString(style.LeftBracket)
+	b.WriteString(strings.Repeat(string(style.Fill), full))
+	empty := width - full
+	if partial {
+		b.WriteRune(style.Partial)
+		empty--
+	}
+	b.WriteString(strings.Repeat(string(style.Empty), empty))
+	b.WriteString(style.RightBracket)
+	return b.String()
+}
@@ -0,0 +1,37 @@
+package rclonelib
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRenderProgressBar(t *testing.T) {
+	def := DefaultProgressBarStyle()
+	block := BlockProgressBarStyle()
+
+	tests := []struct {
+		percent float64
+		width   int
+		style   ProgressBarStyle
+		want    string
+	}{
+		{0, 10, def, "[          ]"},
+		{50, 10, def, "[=====     ]"},
+		{100, 10, def, "[==========]"},
+		{-5, 4, def, "[    ]"},
+		{150, 4, def, "[====]"},
+		{math.NaN(), 4, def, "[    ]"},
+		{99, 10, def, "[========= ]"},
+		{50, 0, def, "[]"},
+		{0, 4, block, "░░░░"},
+		{55, 10, block, "█████▓░░░░"},
+		{50, 10, block, "█████░░░░░"},
+		{100, 4, block, "████"},
+	}
+
+	for _, tt := range tests {
+		if got := RenderProgressBar(tt.percent, tt.width, tt.style); got != tt.want {
+			t.Errorf("RenderProgressBar(%v, %d) = %q, want %q", tt.percent, tt.width, got, tt.want)
+		}
+	}
+}
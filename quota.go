@@ -0,0 +1,42 @@
+package rclonelib
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RemoteQuota is the storage usage reported by `rclone about --json`. Fields
+// are nil when the backend doesn't report them.
+type RemoteQuota struct {
+	Total   *int64 `json:"total,omitempty"`
+	Used    *int64 `json:"used,omitempty"`
+	Free    *int64 `json:"free,omitempty"`
+	Trashed *int64 `json:"trashed,omitempty"`
+	Other   *int64 `json:"other,omitempty"`
+	Objects *int64 `json:"objects,omitempty"`
+}
+
+// GetRemoteQuota returns the quota of the remote holding path using `rclone
+// about --json`. Backends without quota support return
+// ErrRemoteQuotaUnsupported.
+func GetRemoteQuota(ctx context.Context, path string) (*RemoteQuota, error) {
+	if path == "" {
+		return nil, &ValidationError{Field: "path", Message: "path cannot be empty"}
+	}
+
+	output, err := runRclone(ctx, "about", "--json", path)
+	if err != nil {
+		if strings.Contains(err.Error(), "doesn't support about") {
+			return nil, fmt.Errorf("%w: %s", ErrRemoteQuotaUnsupported, path)
+		}
+		return nil, fmt.Errorf("failed to get quota for %s: %w", path, err)
+	}
+
+	var quota RemoteQuota
+	if err := json.Unmarshal(output, &quota); err != nil {
+		return nil, fmt.Errorf("failed to parse about output: %w", err)
+	}
+	return &quota, nil
+}
@@ -0,0 +1,389 @@
+package rclonelib
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RCClient talks to a running rclone remote control server (`rclone rcd` or
+// any rclone started with --rc) over its HTTP API. Methods that aren't
+// wrapped can be reached through Call or RunCommand.
+type RCClient struct {
+	baseURL  string
+	user     string
+	password string
+	client   *http.Client
+
+	// Operations wraps the operations/* methods
+	Operations *RCOperations
+	// Sync wraps the sync/* methods
+	Sync *RCSync
+	// VFS wraps the vfs/* methods, for mounts and serve commands
+	VFS *RCVFS
+}
+
+// NewRCClient returns a client for the rc server at url (e.g.
+// "http://localhost:5572"), using basic authentication when user is set
+func NewRCClient(url, user, password string) *RCClient {
+	c := &RCClient{
+		baseURL:  strings.TrimSuffix(url, "/"),
+		user:     user,
+		password: password,
+		client:   http.DefaultClient,
+	}
+	c.Operations = &RCOperations{c: c}
+	c.Sync = &RCSync{c: c}
+	c.VFS = &RCVFS{c: c}
+	return c
+}
+
+// Call invokes the rc method (e.g. "core/version") with params and decodes
+// the JSON response into out, which may be nil. Errors reported by rclone
+// are returned with its message.
+func (c *RCClient) Call(ctx context.Context, method string, params any, out any) error {
+	if params == nil {
+		params = map[string]any{}
+	}
+	body, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("failed to encode rc parameters: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/"+strings.TrimPrefix(method, "/"), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create rc request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("rc %s failed: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read rc %s response: %w", method, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var rcErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &rcErr) == nil && rcErr.Error != "" {
+			if resp.StatusCode == http.StatusNotFound {
+				return fmt.Errorf("%w: rc %s: %s", ErrNotFound, method, rcErr.Error)
+			}
+			return fmt.Errorf("rc %s failed: %s", method, rcErr.Error)
+		}
+		return fmt.Errorf("rc %s failed: %s", method, resp.Status)
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to parse rc %s response: %w", method, err)
+		}
+	}
+	return nil
+}
+
+// RunCommand runs the rclone command name (e.g. "hashsum") in the rc server
+// via core/command, with opts as its flags, and returns the response
+func (c *RCClient) RunCommand(ctx context.Context, name string, opts map[string]any) (map[string]any, error) {
+	if name == "" {
+		return nil, &ValidationError{Field: "name", Message: "command name cannot be empty"}
+	}
+
+	params := map[string]any{"command": name}
+	if len(opts) > 0 {
+		params["opt"] = opts
+	}
+
+	var result map[string]any
+	if err := c.Call(ctx, "core/command", params, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RCOperations wraps the rc operations/* methods
+type RCOperations struct {
+	c *RCClient
+}
+
+// List lists the directory remote within fs (e.g. fs "mys3:", remote
+// "bucket/dir") using operations/list
+func (o *RCOperations) List(ctx context.Context, fs, remote string) ([]FileInfo, error) {
+	if fs == "" {
+		return nil, &ValidationError{Field: "fs", Message: "fs cannot be empty"}
+	}
+
+	var result struct {
+		List []FileInfo `json:"list"`
+	}
+	params := map[string]any{"fs": fs, "remote": remote}
+	if err := o.c.Call(ctx, "operations/list", params, &result); err != nil {
+		return nil, err
+	}
+	return result.List, nil
+}
+
+// Stat returns information about the file or directory remote within fs
+// using operations/stat. It returns ErrNotFound if nothing exists there.
+func (o *RCOperations) Stat(ctx context.Context, fs, remote string) (*FileInfo, error) {
+	if fs == "" {
+		return nil, &ValidationError{Field: "fs", Message: "fs cannot be empty"}
+	}
+
+	var result struct {
+		Item *FileInfo `json:"item"`
+	}
+	params := map[string]any{"fs": fs, "remote": remote}
+	if err := o.c.Call(ctx, "operations/stat", params, &result); err != nil {
+		return nil, err
+	}
+	if result.Item == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, childPath(fs, remote))
+	}
+	return result.Item, nil
+}
+
+// MkDir creates the directory remote within fs using operations/mkdir
+func (o *RCOperations) MkDir(ctx context.Context, fs, remote string) error {
+	if fs == "" {
+		return &ValidationError{Field: "fs", Message: "fs cannot be empty"}
+	}
+	return o.c.Call(ctx, "operations/mkdir", map[string]any{"fs": fs, "remote": remote}, nil)
+}
+
+// PurgeDir removes the directory remote within fs and everything in it
+// using operations/purge
+func (o *RCOperations) PurgeDir(ctx context.Context, fs, remote string) error {
+	if fs == "" {
+		return &ValidationError{Field: "fs", Message: "fs cannot be empty"}
+	}
+	return o.c.Call(ctx, "operations/purge", map[string]any{"fs": fs, "remote": remote}, nil)
+}
+
+// RCSync wraps the rc sync/* methods
+type RCSync struct {
+	c *RCClient
+}
+
+// Copy starts copying srcRemote within srcFs to dstRemote within dstFs as a
+// background job using sync/copy, and returns the job ID
+func (s *RCSync) Copy(ctx context.Context, srcFs, srcRemote, dstFs, dstRemote string) (int64, error) {
+	if srcFs == "" {
+		return 0, &ValidationError{Field: "srcFs", Message: "source fs cannot be empty"}
+	}
+	if dstFs == "" {
+		return 0, &ValidationError{Field: "dstFs", Message: "destination fs cannot be empty"}
+	}
+
+	// sync/copy works on whole filesystems, so fold the paths into them
+	src, dst := srcFs, dstFs
+	if srcRemote != "" {
+		src = childPath(srcFs, srcRemote)
+	}
+	if dstRemote != "" {
+		dst = childPath(dstFs, dstRemote)
+	}
+
+	var result struct {
+		JobID int64 `json:"jobid"`
+	}
+	params := map[string]any{"srcFs": src, "dstFs": dst, "_async": true}
+	if err := s.c.Call(ctx, "sync/copy", params, &result); err != nil {
+		return 0, err
+	}
+	return result.JobID, nil
+}
+
+// RCVFS wraps the rc vfs/* methods. The fs argument selects the VFS when
+// the server has more than one, e.g. "mys3:bucket"; leave it empty for a
+// single mount.
+type RCVFS struct {
+	c *RCClient
+}
+
+// VFSStats holds the cache statistics reported by vfs/stats
+type VFSStats struct {
+	// BytesInCache is the size of the file data held in the disk cache
+	BytesInCache int64
+	// FilesInCache is the number of files in the disk cache
+	FilesInCache int64
+	// DiskUsage is the space the disk cache takes up. rclone reports a
+	// single figure, so this is currently the same as BytesInCache.
+	DiskUsage int64
+	// CacheMode is the --vfs-cache-mode: "off", "minimal", "writes" or
+	// "full"
+	CacheMode string
+}
+
+// vfsCacheModes are the --vfs-cache-mode values by number, for rclone
+// versions that report the mode as one
+var vfsCacheModes = []string{"off", "minimal", "writes", "full"}
+
+// Stats returns the VFS cache statistics using vfs/stats
+func (v *RCVFS) Stats(ctx context.Context, fs string) (*VFSStats, error) {
+	var result struct {
+		DiskCache struct {
+			BytesUsed int64 `json:"bytesUsed"`
+			Files     int64 `json:"files"`
+		} `json:"diskCache"`
+		Opt struct {
+			CacheMode json.RawMessage `json:"CacheMode"`
+		} `json:"opt"`
+	}
+	if err := v.c.Call(ctx, "vfs/stats", vfsParams(fs), &result); err != nil {
+		return nil, err
+	}
+
+	stats := &VFSStats{
+		BytesInCache: result.DiskCache.BytesUsed,
+		FilesInCache: result.DiskCache.Files,
+		DiskUsage:    result.DiskCache.BytesUsed,
+	}
+	var mode any
+	if json.Unmarshal(result.Opt.CacheMode, &mode) == nil {
+		switch m := mode.(type) {
+		case string:
+			stats.CacheMode = m
+		case float64:
+			if i := int(m); i >= 0 && i < len(vfsCacheModes) {
+				stats.CacheMode = vfsCacheModes[i]
+			}
+		}
+	}
+	return stats, nil
+}
+
+// Forget drops path from the VFS directory cache using vfs/forget, so the
+// next access re-reads it from the remote. End path with "/" to forget a
+// directory and everything below it; an empty path forgets everything.
+func (v *RCVFS) Forget(ctx context.Context, fs, path string) error {
+	params := vfsParams(fs)
+	switch {
+	case path == "":
+	case strings.HasSuffix(path, "/"):
+		params["dir"] = strings.TrimSuffix(path, "/")
+	default:
+		params["file"] = path
+	}
+	return v.c.Call(ctx, "vfs/forget", params, nil)
+}
+
+// Refresh re-reads the directory path (empty for the root) from the remote
+// into the VFS directory cache using vfs/refresh, optionally including every
+// directory below it
+func (v *RCVFS) Refresh(ctx context.Context, fs, path string, recursive bool) error {
+	params := vfsParams(fs)
+	if path != "" {
+		params["dir"] = path
+	}
+	if recursive {
+		params["recursive"] = "true"
+	}
+	return v.c.Call(ctx, "vfs/refresh", params, nil)
+}
+
+// vfsParams returns the parameters selecting the VFS for fs
+func vfsParams(fs string) map[string]any {
+	params := map[string]any{}
+	if fs != "" {
+		params["fs"] = fs
+	}
+	return params
+}
+
+// rcStats is the part of the core/stats response used for progress
+type rcStats struct {
+	Bytes      int64    `json:"bytes"`
+	TotalBytes int64    `json:"totalBytes"`
+	Speed      float64  `json:"speed"`
+	ETA        *float64 `json:"eta"`
+}
+
+// ExecuteWithRC runs the transfer like Execute but reads progress from
+// rclone's remote control API instead of its log output. rclone is started
+// with --rc on a free local port and core/stats is polled every second, so
+// progress tracking doesn't depend on the format of rclone's stats lines.
+// Log lines are still delivered as events.
+func (e *Executor) ExecuteWithRC(id string, opts RcloneOptions) error {
+	addr, err := freeLocalAddr()
+	if err != nil {
+		return fmt.Errorf("failed to find a port for rclone rc: %w", err)
+	}
+
+	flags := make([]string, 0, len(opts.Flags)+4)
+	flags = append(flags, opts.Flags...)
+	opts.Flags = append(flags, "--rc", "--rc-no-auth", "--rc-addr", addr)
+	// Stats lines would duplicate what core/stats reports
+	opts.StatsInterval = "0"
+
+	parent := opts.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	polled := make(chan struct{})
+	go func() {
+		defer close(polled)
+		pollRCStats(ctx, NewRCClient("http://"+addr, "", ""), id, e.manager, time.Second)
+	}()
+
+	err = e.Execute(id, opts)
+	cancel()
+	<-polled
+	return err
+}
+
+// pollRCStats copies core/stats into the manager's progress for id every
+// interval until ctx is done. Failed polls, e.g. before the rc server is
+// listening, are skipped.
+func pollRCStats(ctx context.Context, c *RCClient, id string, mgr *Manager, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		var stats rcStats
+		if err := c.Call(ctx, "core/stats", nil, &stats); err != nil {
+			continue
+		}
+		update := ProgressUpdate{BytesCopied: stats.Bytes, BytesTotal: stats.TotalBytes, Speed: stats.Speed}
+		if stats.TotalBytes > 0 {
+			update.Percent = float64(stats.Bytes) / float64(stats.TotalBytes) * 100
+		}
+		if stats.ETA != nil {
+			update.ETA = time.Duration(*stats.ETA * float64(time.Second))
+		}
+		mgr.UpdateStats(id, update)
+	}
+}
+
+// freeLocalAddr returns a loopback address with a port that was free a
+// moment ago
+func freeLocalAddr() (string, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer l.Close()
+	return l.Addr().String(), nil
+}
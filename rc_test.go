@@ -0,0 +1,133 @@
+package rclonelib
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRC answers rc methods from a table of canned responses and records the
+// parameters each method was called with
+func fakeRC(t *testing.T, responses map[string]string) (*httptest.Server, map[string]map[string]any) {
+	calls := map[string]map[string]any{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := strings.TrimPrefix(r.URL.Path, "/")
+		var params map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			t.Errorf("%s: bad request body: %v", method, err)
+		}
+		calls[method] = params
+
+		resp, ok := responses[method]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"couldn't find method","status":404}`))
+			return
+		}
+		w.Write([]byte(resp))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, calls
+}
+
+func TestRCClient(t *testing.T) {
+	srv, calls := fakeRC(t, map[string]string{
+		"core/command":    `{"result":"ok","error":false}`,
+		"operations/list": `{"list":[{"Path":"dir/a.txt","Name":"a.txt","Size":3,"ModTime":"2024-01-02T15:04:05Z","IsDir":false}]}`,
+		"sync/copy":       `{"jobid":42}`,
+		"operations/stat": `{"item":{"Path":"dir/a.txt","Name":"a.txt","Size":3,"IsDir":false}}`,
+		"vfs/stats":       `{"diskCache":{"bytesUsed":2048,"files":2},"opt":{"CacheMode":"full"}}`,
+		"vfs/refresh":     `{"result":{"dir":"OK"}}`,
+	})
+	c := NewRCClient(srv.URL, "", "")
+	ctx := context.Background()
+
+	result, err := c.RunCommand(ctx, "hashsum", map[string]any{"download": true})
+	if err != nil {
+		t.Fatalf("RunCommand: %v", err)
+	}
+	if result["result"] != "ok" || calls["core/command"]["command"] != "hashsum" {
+		t.Errorf("RunCommand result %v, params %v", result, calls["core/command"])
+	}
+
+	files, err := c.Operations.List(ctx, "remote:", "dir")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(files) != 1 || files[0].Name != "a.txt" || files[0].Size != 3 {
+		t.Errorf("List = %+v", files)
+	}
+
+	info, err := c.Operations.Stat(ctx, "remote:", "dir/a.txt")
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.Name != "a.txt" || calls["operations/stat"]["remote"] != "dir/a.txt" {
+		t.Errorf("Stat = %+v, params %v", info, calls["operations/stat"])
+	}
+
+	vfs, err := c.VFS.Stats(ctx, "")
+	if err != nil {
+		t.Fatalf("VFS.Stats: %v", err)
+	}
+	if vfs.BytesInCache != 2048 || vfs.FilesInCache != 2 || vfs.CacheMode != "full" {
+		t.Errorf("VFS.Stats = %+v", vfs)
+	}
+	if err := c.VFS.Refresh(ctx, "remote:", "dir", true); err != nil {
+		t.Fatalf("VFS.Refresh: %v", err)
+	}
+	if p := calls["vfs/refresh"]; p["fs"] != "remote:" || p["dir"] != "dir" || p["recursive"] != "true" {
+		t.Errorf("vfs/refresh params = %v", p)
+	}
+
+	id, err := c.Sync.Copy(ctx, "src:", "a", "dst:", "b")
+	if err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+	if id != 42 {
+		t.Errorf("job id = %d, want 42", id)
+	}
+	if p := calls["sync/copy"]; p["srcFs"] != "src:a" || p["dstFs"] != "dst:b" || p["_async"] != true {
+		t.Errorf("sync/copy params = %v", p)
+	}
+
+	if err := c.Call(ctx, "no/such", nil, nil); err == nil || !strings.Contains(err.Error(), "couldn't find method") {
+		t.Errorf("expected rclone's error message, got %v", err)
+	}
+}
+
+func TestPollRCStats(t *testing.T) {
+	srv, _ := fakeRC(t, map[string]string{
+		"core/stats": `{"bytes":256,"totalBytes":1024,"speed":100}`,
+	})
+
+	m := NewManager()
+	m.Add("t1", "a", "b")
+	m.Start("t1")
+
+	updates := make(chan Transfer, 16)
+	m.AddNotifier(NotifierFunc(func(tr Transfer) {
+		select {
+		case updates <- tr:
+		default:
+		}
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go pollRCStats(ctx, NewRCClient(srv.URL, "", ""), "t1", m, time.Millisecond)
+
+	select {
+	case tr := <-updates:
+		if tr.BytesCopied != 256 || tr.BytesTotal != 1024 || tr.Progress != 25 {
+			t.Errorf("progress = %.0f%% %d/%d", tr.Progress, tr.BytesCopied, tr.BytesTotal)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("progress was never updated from core/stats")
+	}
+}
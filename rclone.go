@@ -3,11 +3,14 @@ package rclonelib
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
-	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // RcloneCommand represents the type of rclone operation
@@ -24,6 +27,10 @@ const (
 	RcloneMoveTo RcloneCommand = "moveto"
 	// RcloneSync syncs source to destination, changing destination only
 	RcloneSync RcloneCommand = "sync"
+	// RcloneCheck compares source and destination without transferring
+	RcloneCheck RcloneCommand = "check"
+	// RcloneBisync synchronises two paths in both directions
+	RcloneBisync RcloneCommand = "bisync"
 )
 
 // RcloneOptions contains configuration for rclone operations
@@ -40,24 +47,53 @@ type RcloneOptions struct {
 	StatsInterval string
 	// DryRun simulates the operation without making changes
 	DryRun bool
+	// ProgressTerminalTitle makes rclone show progress in the terminal title
+	// (--progress-terminal-title). Execute drops it while the terminal UI is
+	// attached to the manager.
+	ProgressTerminalTitle bool
 	// Context allows cancellation of the operation
 	Context context.Context
+	// Env sets environment variables for rclone, overriding inherited values
+	// with the same name (e.g. RCLONE_CONFIG_MYS3_ACCESS_KEY_ID)
+	Env map[string]string
+	// ClearEnv starts rclone with only Env rather than inheriting this
+	// process's environment, so ambient credentials can't leak in
+	ClearEnv bool
+	// ExitCodePolicy overrides how rclone's exit code is interpreted, e.g. to
+	// accept partial success. nil treats every non-zero exit as an error.
+	ExitCodePolicy *ExitCodePolicy
+	// PreExecute hooks run in order before rclone is started. The first error
+	// aborts the transfer.
+	PreExecute []PreExecuteFunc
 }
 
+// PreExecuteFunc is a hook run by Execute before starting rclone, e.g. to
+// create the destination or check free space
+type PreExecuteFunc func(ctx context.Context, opts RcloneOptions) error
+
 // Executor handles rclone command execution with progress tracking
 type Executor struct {
 	manager *Manager
+	hooks   ExecutionHooks
 }
 
+// ExecutorOption configures an Executor at construction time
+type ExecutorOption func(*Executor)
+
 // NewExecutor creates a new rclone executor
-func NewExecutor(manager *Manager) *Executor {
-	return &Executor{
+func NewExecutor(manager *Manager, opts ...ExecutorOption) *Executor {
+	e := &Executor{
 		manager: manager,
 	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
 }
 
-// Execute runs an rclone command and tracks its progress
-func (e *Executor) Execute(transferID string, opts RcloneOptions) error {
+// BuildRcloneArgs returns the arguments Execute would pass to rclone for
+// opts, without running anything. Useful for logging, debugging and tests.
+func BuildRcloneArgs(opts RcloneOptions) []string {
 	// Build command arguments
 	args := []string{
 		string(opts.Command),
@@ -76,20 +112,92 @@ func (e *Executor) Execute(transferID string, opts RcloneOptions) error {
 		args = append(args, "--dry-run")
 	}
 
+	if opts.ProgressTerminalTitle {
+		args = append(args, "--progress-terminal-title")
+	}
+
 	// Add custom flags
 	args = append(args, opts.Flags...)
 
 	// Add source and destination
 	args = append(args, opts.Source, opts.Destination)
 
+	return args
+}
+
+// BuildRcloneCommand returns the full rclone command line for opts, quoted
+// so it can be pasted into a POSIX shell
+func BuildRcloneCommand(opts RcloneOptions) string {
+	args := BuildRcloneArgs(opts)
+	quoted := make([]string, 0, len(args)+1)
+	quoted = append(quoted, "rclone")
+	for _, arg := range args {
+		quoted = append(quoted, shellQuote(arg))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// shellQuote quotes s for a POSIX shell if it contains anything other than
+// characters that are always safe unquoted
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	safe := true
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+			strings.ContainsRune("-_./:=@%+,", r)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Execute runs an rclone command and tracks its progress
+func (e *Executor) Execute(transferID string, opts RcloneOptions) error {
+	e.manager.recordOptions(transferID, opts)
+	if e.hooks.BeforeExecute != nil {
+		e.hooks.BeforeExecute(transferID, opts)
+	}
+	err := e.execute(transferID, opts)
+	if e.hooks.AfterExecute != nil {
+		e.hooks.AfterExecute(transferID, opts, err)
+	}
+	return err
+}
+
+func (e *Executor) execute(transferID string, opts RcloneOptions) error {
 	// Create context if not provided
 	ctx := opts.Context
 	if ctx == nil {
 		ctx = context.Background()
 	}
 
+	if err := ValidateStatsInterval(opts.StatsInterval); err != nil {
+		return err
+	}
+
+	for _, hook := range opts.PreExecute {
+		if err := hook(ctx, opts); err != nil {
+			return fmt.Errorf("pre-execute check failed: %w", err)
+		}
+	}
+
+	opts = e.manager.applyAutoTune(ctx, opts)
+	if e.manager.tuiAttached() {
+		opts = withoutTerminalTitle(opts)
+	}
+	args := BuildRcloneArgs(opts)
+
 	// Create command
 	cmd := exec.CommandContext(ctx, "rclone", args...)
+	if len(opts.Env) > 0 || opts.ClearEnv {
+		cmd.Env = buildEnv(opts.Env, opts.ClearEnv)
+	}
 
 	// Create pipe for stderr (where "Transferred:" lines go with -v flag)
 	stderr, err := cmd.StderrPipe()
@@ -122,10 +230,153 @@ func (e *Executor) Execute(transferID string, opts RcloneOptions) error {
 	// only sees the exit code, so a stalled/errored transfer is indistinguishable
 	// from a silent hang.
 	if cmdErr != nil && len(stderrTail) > 0 {
-		return fmt.Errorf("%w: %s", cmdErr, strings.Join(stderrTail, "; "))
+		err := fmt.Errorf("%w: %s", cmdErr, strings.Join(stderrTail, "; "))
+		// A full destination won't fix itself; type it so retries stop.
+		if isNoSpaceMessage(err.Error()) {
+			return opts.ExitCodePolicy.apply(&ErrNoSpaceLeft{Path: opts.Destination, Err: err})
+		}
+		return opts.ExitCodePolicy.apply(err)
+	}
+
+	return opts.ExitCodePolicy.apply(cmdErr)
+}
+
+// ExecuteWithTimeout runs Execute with a deadline of timeout, derived from
+// opts.Context when set. If the deadline is what stopped the transfer the
+// error wraps ErrTransferTimeout as well as rclone's own error.
+func (e *Executor) ExecuteWithTimeout(transferID string, opts RcloneOptions, timeout time.Duration) error {
+	parent := opts.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+	opts.Context = ctx
+
+	err := e.Execute(transferID, opts)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) && parent.Err() == nil {
+		return fmt.Errorf("%w after %s: %w", ErrTransferTimeout, timeout, err)
+	}
+	return err
+}
+
+// errNoUpdate makes an update function leave the transfer untouched
+var errNoUpdate = errors.New("no update")
+
+// ExecuteOnce runs the transfer unless it has already succeeded, managing
+// its status itself: don't call Start, Complete or Fail around it.
+//
+// A completed transfer returns nil without running rclone. A pending or
+// failed one is (re)started, executed and marked completed or failed. One
+// already in progress, e.g. from a concurrent ExecuteOnce, is waited for and
+// its result returned. This makes it safe to call repeatedly from retry
+// loops and idempotent pipelines.
+func (e *Executor) ExecuteOnce(id string, opts RcloneOptions) error {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if _, exists := e.manager.Get(id); !exists {
+		return fmt.Errorf("%w: %s", ErrTransferNotFound, id)
+	}
+
+	waited := false
+	for {
+		var (
+			wait   chan struct{}
+			done   bool
+			result error
+		)
+		err := e.manager.update(id, func(t *Transfer) error {
+			switch {
+			case t.Status == StatusCompleted:
+				done = true
+				return errNoUpdate
+			case t.Status == StatusFailed && waited:
+				// It failed while we waited; report that rather than retry,
+				// so concurrent callers see the same outcome.
+				done, result = true, t.Error
+				return errNoUpdate
+			case t.Status == StatusInProgress:
+				wait = e.manager.changed
+				return errNoUpdate
+			}
+			if e.manager.draining {
+				return ErrManagerDraining
+			}
+			t.reset()
+			t.Status = StatusInProgress
+			t.StartTime = time.Now()
+			return nil
+		})
+		if err != nil && !errors.Is(err, errNoUpdate) {
+			return err
+		}
+
+		if done {
+			return result
+		}
+		if wait != nil {
+			waited = true
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-wait:
+			}
+			continue
+		}
+
+		err = e.Execute(id, opts)
+		if err != nil {
+			e.manager.Fail(id, err)
+		} else {
+			e.manager.Complete(id)
+		}
+		return err
+	}
+}
+
+// withoutTerminalTitle strips --progress-terminal-title from opts. The
+// Bubble Tea UI runs in the alternate screen and rclone rewriting the title
+// underneath it fights with the UI.
+func withoutTerminalTitle(opts RcloneOptions) RcloneOptions {
+	opts.ProgressTerminalTitle = false
+
+	flags := make([]string, 0, len(opts.Flags))
+	for _, f := range opts.Flags {
+		if f != "--progress-terminal-title" {
+			flags = append(flags, f)
+		}
+	}
+	opts.Flags = flags
+	return opts
+}
+
+// buildEnv returns the environment for an rclone process: os.Environ()
+// (unless clear is set) with env overlaid. The result is never nil, since a
+// nil exec.Cmd.Env means "inherit".
+func buildEnv(env map[string]string, clear bool) []string {
+	result := []string{}
+	if !clear {
+		for _, kv := range os.Environ() {
+			key, _, _ := strings.Cut(kv, "=")
+			if _, overridden := env[key]; !overridden {
+				result = append(result, kv)
+			}
+		}
 	}
 
-	return cmdErr
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		result = append(result, key+"="+env[key])
+	}
+	return result
 }
 
 // parseRcloneOutput parses rclone output to extract progress information and
@@ -171,10 +422,6 @@ func parseRcloneOutput(reader *bufio.Reader, transferID string, mgr *Manager) []
 		return 0, nil, nil
 	})
 
-	// Regex to match "Transferred:" lines with full details including speed and ETA
-	// Example: "Transferred:   1.234 GiB / 5.678 GiB, 22%, 10 MiB/s, ETA 1m30s"
-	statsRegex := regexp.MustCompile(`Transferred:\s+([0-9.]+)\s*([kKMGTP]i?[Bb]?)\s*/\s*([0-9.]+)\s*([kKMGTP]i?[Bb]?),\s*([0-9]+)%`)
-
 	// Ring buffer of the most recent non-progress lines, bounded so a chatty
 	// transfer can't grow this without limit.
 	const maxTail = 10
@@ -187,19 +434,23 @@ func parseRcloneOutput(reader *bufio.Reader, transferID string, mgr *Manager) []
 		}
 
 		// Try to match progress line
-		matches := statsRegex.FindStringSubmatch(line)
-		if len(matches) >= 6 {
-			// Parse percentage
-			percentage, err := strconv.ParseFloat(matches[5], 64)
-			if err == nil {
-				// Parse bytes with proper unit handling
-				copied := parseSize(matches[1], matches[2])
-				total := parseSize(matches[3], matches[4])
-				mgr.UpdateProgress(transferID, percentage, copied, total)
-			}
+		if update, ok := ParseTransferOutput(line); ok {
+			mgr.UpdateStats(transferID, *update)
 			continue // progress line: not useful as diagnostic text
 		}
 
+		if _, total, ok := (S3MultipartParser{}).ParseLine(line); ok {
+			mgr.recordMultipartPart(transferID, total)
+		}
+
+		if retryAfter, ok := parseRateLimit(line); ok {
+			mgr.recordRateLimit(transferID, retryAfter)
+		}
+
+		if ev, ok := parseLogLine(line); ok {
+			mgr.recordLog(transferID, ev)
+		}
+
 		// Non-progress line (errors, warnings, summary): keep the last maxTail.
 		if len(tail) == maxTail {
 			tail = tail[1:]
@@ -2,9 +2,12 @@ package rclonelib
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 )
 
 // feed builds a bufio.Reader over s for parseRcloneOutput.
@@ -29,6 +32,9 @@ func TestParseRcloneOutput_ReturnsErrorTailNotProgress(t *testing.T) {
 	if tr, ok := mgr.Get("t1"); !ok || tr.Progress != 35 {
 		t.Fatalf("expected progress 35 from last stats line, got %+v (ok=%v)", tr, ok)
 	}
+	if tr, _ := mgr.Get("t1"); tr.CurrentSpeedBPS != 10<<20 || tr.ETA != time.Minute {
+		t.Errorf("speed %d, ETA %v; want rclone's 10 MiB/s and 1m", tr.CurrentSpeedBPS, tr.ETA)
+	}
 
 	// Tail must contain the diagnostic lines and none of the progress lines.
 	joined := strings.Join(tail, "\n")
@@ -64,3 +70,269 @@ func TestParseRcloneOutput_TailIsBounded(t *testing.T) {
 		t.Errorf("expected last 10 lines (90..99), got first=%q last=%q", tail[0], tail[9])
 	}
 }
+
+func TestParseRcloneOutput_RecordsRateLimit(t *testing.T) {
+	mgr := NewManager()
+	mgr.Add("t1", "/local/file", "gdrive:backup")
+	events := mgr.Subscribe()
+
+	input := "2024/01/02 15:04:05 ERROR : file: Too many requests. Trying again in 12 seconds\n"
+	parseRcloneOutput(feed(input), "t1", mgr)
+
+	select {
+	case ev := <-events:
+		if ev.TransferID != "t1" || ev.RateLimit == nil {
+			t.Fatalf("expected rate-limit event for t1, got %+v", ev)
+		}
+		if ev.RateLimit.Remote != "gdrive" || ev.RateLimit.RetryAfter != 12*time.Second {
+			t.Errorf("unexpected rate-limit event: %+v", *ev.RateLimit)
+		}
+	default:
+		t.Fatal("expected a rate-limit event")
+	}
+
+	if tr, _ := mgr.Get("t1"); tr.RateLimit == nil {
+		t.Error("expected rate-limit event to be stored on the transfer")
+	}
+}
+
+func TestParseRcloneOutput_EmitsLogEvents(t *testing.T) {
+	mgr := NewManager()
+	mgr.Add("t1", "/local/file", "remote:backup")
+	logs := mgr.LogSubscribe()
+
+	input := "2024/01/02 15:04:05 INFO  : file.txt: Copied (new)\n" +
+		"Transferred:   1 MiB / 1 MiB, 100%, 1 MiB/s, ETA 0s\n" +
+		"2024/01/02 15:04:06 ERROR : other.txt: failed to copy\n"
+	parseRcloneOutput(feed(input), "t1", mgr)
+
+	var got []LogEvent
+	for len(logs) > 0 {
+		got = append(got, <-logs)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 log events, got %d: %+v", len(got), got)
+	}
+	if got[0].Level != "INFO" || got[0].Message != "file.txt: Copied (new)" || got[0].TransferID != "t1" {
+		t.Errorf("unexpected first log event: %+v", got[0])
+	}
+	if got[1].Level != "ERROR" || got[1].Time.Second() != 6 {
+		t.Errorf("unexpected second log event: %+v", got[1])
+	}
+}
+
+func TestParseRcloneOutput_TracksMultipart(t *testing.T) {
+	mgr := NewManager()
+	mgr.Add("t1", "/local/big.iso", "s3:bucket")
+
+	input := "2024/01/02 15:04:05 DEBUG : big.iso: Multipart: Part [1/4]\n" +
+		"2024/01/02 15:04:05 DEBUG : big.iso: multi-thread copy: chunk 3/4 (0-5242880) size 5Mi starting\n" +
+		"2024/01/02 15:04:05 DEBUG : chunker: chunk 2/4 of big.iso\n" +
+		"2024/01/02 15:04:06 DEBUG : big.iso: multi-thread copy: chunk 3/4 (0-5242880) size 5Mi finished\n"
+	parseRcloneOutput(feed(input), "t1", mgr)
+
+	tr, _ := mgr.Get("t1")
+	if !tr.IsMultipart() {
+		t.Fatal("expected transfer to be multipart")
+	}
+	if tr.Multipart.CompletedParts != 2 || tr.Multipart.TotalParts != 4 {
+		t.Errorf("unexpected multipart progress: %+v", *tr.Multipart)
+	}
+}
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		line string
+		want time.Duration
+		ok   bool
+	}{
+		{"ERROR : Too many requests. Trying again in 12 seconds", 12 * time.Second, true},
+		{"NOTICE: rate limit exceeded, retry after 1m30s", 90 * time.Second, true},
+		{"DEBUG : pacer: rate: Wait(n=1) would exceed context deadline", 0, true},
+		{"INFO  : file.txt: Copied (new)", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRateLimit(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseRateLimit(%q) = %v, %v; want %v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestBuildRcloneArgs(t *testing.T) {
+	opts := RcloneOptions{
+		Command:     RcloneCopy,
+		Source:      "/data/my file.txt",
+		Destination: "remote:backup",
+		Flags:       []string{"--transfers", "4"},
+		DryRun:      true,
+	}
+
+	got := strings.Join(BuildRcloneArgs(opts), " ")
+	want := "copy -v --stats 500ms --dry-run --transfers 4 /data/my file.txt remote:backup"
+	if got != want {
+		t.Errorf("BuildRcloneArgs() = %q, want %q", got, want)
+	}
+}
+
+func TestBuildRcloneCommand_QuotesArguments(t *testing.T) {
+	opts := RcloneOptions{
+		Command:       RcloneCopyTo,
+		Source:        "/data/it's here.txt",
+		Destination:   "remote:backup/file.txt",
+		StatsInterval: "1s",
+	}
+
+	got := BuildRcloneCommand(opts)
+	want := `rclone copyto -v --stats 1s '/data/it'\''s here.txt' remote:backup/file.txt`
+	if got != want {
+		t.Errorf("BuildRcloneCommand() = %q, want %q", got, want)
+	}
+}
+
+func TestExecutionHooks(t *testing.T) {
+	errCheck := errors.New("check failed")
+	var calls []string
+
+	e := NewExecutor(NewManager(), WithHooks(ExecutionHooks{
+		BeforeExecute: func(id string, opts RcloneOptions) {
+			calls = append(calls, "before "+id)
+		},
+		AfterExecute: func(id string, opts RcloneOptions, err error) {
+			if !errors.Is(err, errCheck) {
+				t.Errorf("AfterExecute got err %v", err)
+			}
+			calls = append(calls, "after "+id)
+		},
+		BeforeRetry: func(id string, attempt int, delay time.Duration) {
+			calls = append(calls, fmt.Sprintf("retry %s %d", id, attempt))
+		},
+	}))
+
+	opts := NewRcloneOptions(Source("a"), Destination("b"), PreExecute(func(ctx context.Context, opts RcloneOptions) error {
+		return errCheck
+	}))
+	cfg := RetryConfig{MaxAttempts: 2, InitialDelay: time.Millisecond}
+	if err := e.ExecuteWithRetry("t1", opts, cfg); !errors.Is(err, errCheck) {
+		t.Fatalf("expected check error, got %v", err)
+	}
+
+	want := []string{"before t1", "after t1", "retry t1 2", "before t1", "after t1"}
+	if fmt.Sprint(calls) != fmt.Sprint(want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestExecuteOnce(t *testing.T) {
+	errCheck := errors.New("check failed")
+	runs := 0
+	opts := NewRcloneOptions(Source("a"), Destination("b"), PreExecute(func(ctx context.Context, opts RcloneOptions) error {
+		runs++
+		return errCheck
+	}))
+
+	m := NewManager()
+	e := NewExecutor(m)
+
+	m.Add("done", "a", "b")
+	m.Complete("done")
+	if err := e.ExecuteOnce("done", opts); err != nil || runs != 0 {
+		t.Errorf("completed transfer: err %v, runs %d", err, runs)
+	}
+
+	m.Add("failed", "a", "b")
+	m.Fail("failed", errors.New("earlier failure"))
+	if err := e.ExecuteOnce("failed", opts); !errors.Is(err, errCheck) || runs != 1 {
+		t.Errorf("failed transfer should re-run: err %v, runs %d", err, runs)
+	}
+	if tr, _ := m.Get("failed"); tr.Status != StatusFailed || !errors.Is(tr.Error, errCheck) {
+		t.Errorf("status %s, error %v", tr.Status, tr.Error)
+	}
+
+	if err := e.ExecuteOnce("missing", opts); err == nil {
+		t.Error("expected error for unknown transfer")
+	}
+}
+
+func TestHasHTTPCode(t *testing.T) {
+	codes := (&RetryConfig{}).WithRetryOnRateLimit().RetryOnHTTPCodes
+
+	tests := []struct {
+		msg  string
+		want bool
+	}{
+		{"Failed to copy: HTTP 429 Too Many Requests", true},
+		{"googleapi: Error 503: backend error", true},
+		{"status code 500", false},
+		{"copied 14290 bytes", false},
+		{"429", true},
+	}
+	for _, tt := range tests {
+		if got := hasHTTPCode(tt.msg, codes); got != tt.want {
+			t.Errorf("hasHTTPCode(%q) = %v, want %v", tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestIsNoSpaceMessage(t *testing.T) {
+	driveRateLimit := "googleapi: Error 403: Quota exceeded for quota metric 'Queries' and limit " +
+		"'Queries per minute per user' of service 'drive.googleapis.com' for consumer " +
+		"'project_number:123456'., rateLimitExceeded"
+
+	tests := []struct {
+		msg  string
+		want bool
+	}{
+		{"write /data/file: no space left on device", true},
+		{"googleapi: Error 403: The user's Drive storage quota has been exceeded., storageQuotaExceeded", true},
+		{driveRateLimit, false},
+		{"googleapi: Error 403: User Rate Limit Exceeded, userRateLimitExceeded", false},
+	}
+	for _, tt := range tests {
+		if got := isNoSpaceMessage(tt.msg); got != tt.want {
+			t.Errorf("isNoSpaceMessage(%q) = %v, want %v", tt.msg, got, tt.want)
+		}
+	}
+
+	if ce := ClassifyError(errors.New(driveRateLimit)); ce.Type == ErrorTypeInsufficientSpace {
+		t.Errorf("Drive rate limit classified as %s", ce.Type)
+	}
+}
+
+func TestParseTransferOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		percent float64
+		copied  int64
+		eta     time.Duration
+	}{
+		{"verbose", "Transferred:   1 GiB / 4 GiB, 25%, 10 MiB/s, ETA 1m30s", 25, 1 << 30, 90 * time.Second},
+		{"progress", "\x1b[2K\x1b[1GTransferred:   \t  512 MiB / 1 GiB, 50%, 5 MiB/s, ETA 1d2h", 50, 512 << 20, 26 * time.Hour},
+		{"one-line", "2024/01/02 15:04:05 INFO  :    3 KiB / 4 KiB, 75%, 1 KiB/s, ETA 1s", 75, 3 << 10, time.Second},
+		{"json-log", `{"level":"info","msg":"stats","stats":{"bytes":100,"totalBytes":400,"speed":10,"eta":30}}`, 25, 100, 30 * time.Second},
+		{"unknown total", "Transferred:   0 B / 0 B, -, 0 B/s, ETA -", 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseTransferOutput(tt.line)
+			if !ok {
+				t.Fatalf("line not recognised: %q", tt.line)
+			}
+			if got.Percent != tt.percent || got.BytesCopied != tt.copied || got.ETA != tt.eta {
+				t.Errorf("got %+v, want %v%% %d bytes ETA %v", *got, tt.percent, tt.copied, tt.eta)
+			}
+		})
+	}
+
+	for _, line := range []string{
+		"Transferred:            5 / 10, 50%",
+		"2024/01/02 15:04:05 ERROR : file.txt: failed to copy",
+		`{"level":"info","msg":"Copied (new)"}`,
+	} {
+		if got, ok := ParseTransferOutput(line); ok {
+			t.Errorf("ParseTransferOutput(%q) = %+v, want no match", line, *got)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package rclonetest
+
+import (
+	"context"
+	"testing"
+
+	rclonelib "github.com/joshkerr/rclone-golib"
+)
+
+// MakeTestDir returns a temporary directory filled with reproducible test
+// data generated by rclonelib.MakeTestFiles. It is removed when the test
+// ends. The test fails if rclone can't generate the files.
+func MakeTestDir(t testing.TB, opts rclonelib.MakeFilesOptions) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := rclonelib.MakeTestFiles(context.Background(), dir, opts); err != nil {
+		t.Fatalf("rclonetest: %v", err)
+	}
+	return dir
+}
@@ -0,0 +1,147 @@
+// Package rclonetest provides helpers for testing code built on rclonelib
+// against local fixtures instead of real cloud remotes.
+package rclonetest
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestHTTPServer is an in-memory HTTP file server with an rclone remote of
+// type http pointing at it
+type TestHTTPServer struct {
+	// URL is the base URL of the server
+	URL string
+	// Remote is the name of the rclone remote, e.g. "testhttp"; use it as
+	// Remote + ":path/to/file"
+	Remote string
+	// ConfigPath is the temporary rclone config file defining Remote
+	ConfigPath string
+
+	server *httptest.Server
+
+	mu      sync.RWMutex
+	files   map[string]string
+	modTime time.Time
+}
+
+// NewTestHTTPServer starts a TestHTTPServer and writes a temporary rclone
+// config defining its remote. RCLONE_CONFIG is pointed at that config for
+// the duration of the test, so rclonelib functions see the remote without
+// further setup; as with t.Setenv, the test must not be parallel. The server
+// is shut down when the test ends, or earlier by calling Close.
+func NewTestHTTPServer(t testing.TB) *TestHTTPServer {
+	t.Helper()
+
+	s := &TestHTTPServer{
+		Remote:  "testhttp",
+		files:   make(map[string]string),
+		modTime: time.Now().UTC().Truncate(time.Second),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serve))
+	s.URL = s.server.URL
+	t.Cleanup(s.Close)
+
+	s.ConfigPath = filepath.Join(t.TempDir(), "rclone.conf")
+	config := fmt.Sprintf("[%s]\ntype = http\nurl = %s/\n", s.Remote, s.URL)
+	if err := os.WriteFile(s.ConfigPath, []byte(config), 0o600); err != nil {
+		t.Fatalf("rclonetest: failed to write config: %v", err)
+	}
+	t.Setenv("RCLONE_CONFIG", s.ConfigPath)
+
+	return s
+}
+
+// AddFile serves content at path, e.g. "dir/file.txt". Parent directories
+// are listed automatically.
+func (s *TestHTTPServer) AddFile(path, content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[strings.TrimPrefix(path, "/")] = content
+}
+
+// Close shuts the server down. It is safe to call more than once.
+func (s *TestHTTPServer) Close() {
+	s.server.Close()
+}
+
+// serve answers GET and HEAD for files, and returns an HTML index, which is
+// what rclone's http backend parses, for directories
+func (s *TestHTTPServer) serve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	name := strings.TrimPrefix(r.URL.Path, "/")
+	if content, ok := s.files[name]; ok {
+		w.Header().Set("Last-Modified", s.modTime.Format(http.TimeFormat))
+		http.ServeContent(w, r, path.Base(name), s.modTime, strings.NewReader(content))
+		return
+	}
+
+	entries := s.listLocked(name)
+	if entries == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if !strings.HasSuffix(r.URL.Path, "/") {
+		http.Redirect(w, r, r.URL.Path+"/", http.StatusMovedPermanently)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintln(w, "<html><body><ul>")
+	for _, entry := range entries {
+		fmt.Fprintf(w, "<li><a href=\"%s\">%s</a></li>\n", html.EscapeString(entry), html.EscapeString(entry))
+	}
+	fmt.Fprintln(w, "</ul></body></html>")
+}
+
+// listLocked returns the sorted entries of directory dir, with a trailing
+// slash on subdirectories, or nil if nothing is below it. The caller must
+// hold mu.
+func (s *TestHTTPServer) listLocked(dir string) []string {
+	prefix := strings.TrimSuffix(dir, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	seen := make(map[string]bool)
+	for name := range s.files {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+		if i := strings.Index(rest, "/"); i >= 0 {
+			rest = rest[:i+1]
+		}
+		seen[rest] = true
+	}
+	if len(seen) == 0 {
+		if prefix == "" {
+			return []string{}
+		}
+		return nil
+	}
+
+	entries := make([]string, 0, len(seen))
+	for entry := range seen {
+		entries = append(entries, entry)
+	}
+	sort.Strings(entries)
+	return entries
+}
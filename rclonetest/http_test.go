@@ -0,0 +1,52 @@
+package rclonetest
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestTestHTTPServer(t *testing.T) {
+	s := NewTestHTTPServer(t)
+	s.AddFile("dir/a.txt", "hello")
+	s.AddFile("dir/sub/b.txt", "world")
+
+	resp, err := http.Get(s.URL + "/dir/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "hello" {
+		t.Errorf("file body = %q, want hello", body)
+	}
+
+	resp, err = http.Get(s.URL + "/dir/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), `href="a.txt"`) || !strings.Contains(string(body), `href="sub/"`) {
+		t.Errorf("directory listing missing entries:\n%s", body)
+	}
+
+	resp, err = http.Get(s.URL + "/missing.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("missing file status = %d, want 404", resp.StatusCode)
+	}
+
+	config, err := os.ReadFile(s.ConfigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(config), "type = http") || os.Getenv("RCLONE_CONFIG") != s.ConfigPath {
+		t.Errorf("config not set up: %s", config)
+	}
+}
@@ -0,0 +1,85 @@
+package rclonelib
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// recordOptions remembers the options a transfer was executed with
+func (m *Manager) recordOptions(id string, opts RcloneOptions) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.transfers[id]; !exists {
+		return
+	}
+	if m.options == nil {
+		m.options = make(map[string]RcloneOptions)
+	}
+	m.options[id] = opts
+}
+
+// Options returns the RcloneOptions a transfer was last executed with by an
+// Executor, or those copied to it by Replay
+func (m *Manager) Options(id string) (RcloneOptions, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	opts, ok := m.options[id]
+	return opts, ok
+}
+
+// Replay queues a new pending transfer repeating a completed one, e.g. to
+// re-sync after the source has changed. The new transfer gets the ID
+// "<id>_replay_<n>", the same source, destination, priority and annotations,
+// ReplayOf set to id, and a copy of the original's options (see Options).
+// The copy has no Context, since the original's may well have been
+// cancelled by now; callers must set a fresh one before executing it.
+// Failed transfers should be retried with Reset instead.
+func (m *Manager) Replay(id string) (string, error) {
+	m.mu.Lock()
+
+	orig, exists := m.transfers[id]
+	if !exists {
+		m.mu.Unlock()
+		return "", fmt.Errorf("%w: %s", ErrTransferNotFound, id)
+	}
+	if orig.Status != StatusCompleted {
+		m.mu.Unlock()
+		return "", &ValidationError{
+			Field:   "status",
+			Message: fmt.Sprintf("only completed transfers can be replayed, %s is %s", id, orig.Status),
+		}
+	}
+
+	newID := ""
+	for n := 1; ; n++ {
+		newID = id + "_replay_" + strconv.Itoa(n)
+		if _, taken := m.transfers[newID]; !taken {
+			break
+		}
+	}
+
+	t := &Transfer{
+		ID:          newID,
+		Source:      orig.Source,
+		Destination: orig.Destination,
+		Status:      StatusPending,
+		Priority:    orig.Priority,
+		Annotations: orig.Annotations,
+		ReplayOf:    id,
+	}
+	m.transfers[newID] = t
+	m.order = append(m.order, newID)
+	if opts, ok := m.options[id]; ok {
+		opts.Context = nil
+		m.options[newID] = opts
+	}
+	m.broadcastLocked()
+
+	snapshot, notifiers := *t, m.notifiers
+	m.mu.Unlock()
+
+	notifyAll(notifiers, snapshot)
+	return newID, nil
+}
@@ -0,0 +1,151 @@
+package rclonelib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ResumeEvent records that a transfer is resuming an earlier, interrupted
+// attempt
+type ResumeEvent struct {
+	Destination string
+	// BytesTransferred is how much the previous attempt had copied, if known
+	BytesTransferred int64
+	// StartedAt is when the first attempt started, if known
+	StartedAt time.Time
+}
+
+// ResumeState is the sidecar file ResumableTransfer keeps next to a local
+// destination while a transfer is incomplete
+type ResumeState struct {
+	TransferID       string    `json:"transfer_id"`
+	Source           string    `json:"source"`
+	Destination      string    `json:"destination"`
+	BytesTransferred int64     `json:"bytes_transferred"`
+	StartedAt        time.Time `json:"started_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// ResumableTransfer runs transfers through an Executor, remembering
+// interrupted ones so a later attempt can pick up where they left off.
+//
+// Before each transfer it looks for partial files (see HasPartialFiles) or
+// a sidecar state file at the destination. If either is found it emits a
+// ResumeEvent and passes --no-update-modtime, so rclone leaves the files
+// the earlier attempt finished untouched. It's rclone's usual comparison
+// (size plus modification time or hash) that skips those files; that
+// happens with or without the flag. The sidecar is updated if the transfer
+// fails and removed once it succeeds.
+//
+// Resume detection only works for local destinations; remote destinations
+// are transferred normally.
+type ResumableTransfer struct {
+	manager  *Manager
+	executor *Executor
+}
+
+// NewResumableTransfer creates a ResumableTransfer using executor, which
+// must report progress to manager
+func NewResumableTransfer(manager *Manager, executor *Executor) *ResumableTransfer {
+	return &ResumableTransfer{manager: manager, executor: executor}
+}
+
+// Execute runs the transfer, resuming a previous attempt if one is detected
+func (r *ResumableTransfer) Execute(transferID string, opts RcloneOptions) error {
+	if IsRemotePath(opts.Destination) {
+		return r.executor.Execute(transferID, opts)
+	}
+
+	sidecar := ResumeStatePath(opts.Destination)
+	state, err := LoadResumeState(sidecar)
+	if err != nil {
+		return err
+	}
+
+	partial, _ := HasPartialFiles(opts.Destination)
+	if state != nil || partial {
+		ev := &ResumeEvent{Destination: opts.Destination}
+		if state != nil {
+			ev.BytesTransferred = state.BytesTransferred
+			ev.StartedAt = state.StartedAt
+		}
+		r.manager.recordResume(transferID, ev)
+
+		flags := make([]string, 0, len(opts.Flags)+1)
+		flags = append(flags, opts.Flags...)
+		opts.Flags = append(flags, "--no-update-modtime")
+	}
+
+	if state == nil {
+		state = &ResumeState{
+			TransferID:  transferID,
+			Source:      opts.Source,
+			Destination: opts.Destination,
+			StartedAt:   time.Now(),
+		}
+	}
+	state.UpdatedAt = time.Now()
+	if err := saveResumeState(sidecar, state); err != nil {
+		return err
+	}
+
+	execErr := r.executor.Execute(transferID, opts)
+	if execErr == nil {
+		if err := os.Remove(sidecar); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove resume state: %w", err)
+		}
+		return nil
+	}
+
+	if t, ok := r.manager.copyOf(transferID); ok && t.BytesCopied > state.BytesTransferred {
+		state.BytesTransferred = t.BytesCopied
+	}
+	state.UpdatedAt = time.Now()
+	saveResumeState(sidecar, state)
+
+	return execErr
+}
+
+// ResumeStatePath returns where ResumableTransfer keeps the sidecar state
+// file for a local destination: a hidden file in the same directory
+func ResumeStatePath(destination string) string {
+	clean := filepath.Clean(destination)
+	return filepath.Join(filepath.Dir(clean), "."+filepath.Base(clean)+".rclonelib-resume.json")
+}
+
+// LoadResumeState reads a sidecar state file, returning nil if it doesn't
+// exist
+func LoadResumeState(path string) (*ResumeState, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resume state: %w", err)
+	}
+
+	var state ResumeState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse resume state: %w", err)
+	}
+	return &state, nil
+}
+
+// saveResumeState writes a sidecar state file
+func saveResumeState(path string, state *ResumeState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode resume state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create resume state directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write resume state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package rclonelib
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResumableTransfer_SavesAndResumes(t *testing.T) {
+	errCheck := errors.New("interrupted")
+	dst := filepath.Join(t.TempDir(), "out")
+	sidecar := ResumeStatePath(dst)
+
+	m := NewManager()
+	m.Add("t1", "remote:src", dst)
+	r := NewResumableTransfer(m, NewExecutor(m))
+
+	// First attempt copies some data then fails.
+	opts := NewRcloneOptions(Source("remote:src"), Destination(dst), PreExecute(func(ctx context.Context, opts RcloneOptions) error {
+		m.UpdateProgress("t1", 25, 1234, 5000)
+		return errCheck
+	}))
+	if err := r.Execute("t1", opts); !errors.Is(err, errCheck) {
+		t.Fatalf("expected interruption, got %v", err)
+	}
+
+	state, err := LoadResumeState(sidecar)
+	if err != nil || state == nil {
+		t.Fatalf("LoadResumeState = %v, %v", state, err)
+	}
+	if state.TransferID != "t1" || state.Destination != dst || state.BytesTransferred != 1234 {
+		t.Errorf("unexpected state %+v", state)
+	}
+
+	// The second attempt is told it's resuming.
+	events := m.Subscribe()
+	var flags []string
+	opts = NewRcloneOptions(Source("remote:src"), Destination(dst), PreExecute(func(ctx context.Context, opts RcloneOptions) error {
+		flags = opts.Flags
+		return errCheck
+	}))
+	r.Execute("t1", opts)
+
+	if !hasFlag(flags, "--no-update-modtime") {
+		t.Errorf("resumed transfer flags %v missing --no-update-modtime", flags)
+	}
+	select {
+	case ev := <-events:
+		if ev.Resume == nil || ev.Resume.BytesTransferred != 1234 || !ev.Resume.StartedAt.Equal(state.StartedAt) {
+			t.Errorf("unexpected event %+v", ev)
+		}
+	default:
+		t.Error("no resume event emitted")
+	}
+}
+
+func TestResumableTransfer_NoStateNoResume(t *testing.T) {
+	dst := filepath.Join(t.TempDir(), "out")
+	m := NewManager()
+	m.Add("t1", "remote:src", dst)
+	r := NewResumableTransfer(m, NewExecutor(m))
+
+	var flags []string
+	opts := NewRcloneOptions(Source("remote:src"), Destination(dst), PreExecute(func(ctx context.Context, opts RcloneOptions) error {
+		flags = opts.Flags
+		return errors.New("stop")
+	}))
+	r.Execute("t1", opts)
+
+	if hasFlag(flags, "--no-update-modtime") {
+		t.Errorf("fresh transfer should not be resumed, flags %v", flags)
+	}
+	if _, err := os.Stat(ResumeStatePath(dst)); err != nil {
+		t.Errorf("failed transfer should leave a sidecar: %v", err)
+	}
+}
+
+func TestLoadResumeState_Missing(t *testing.T) {
+	state, err := LoadResumeState(filepath.Join(t.TempDir(), "missing.json"))
+	if state != nil || err != nil {
+		t.Errorf("LoadResumeState = %v, %v; want nil, nil", state, err)
+	}
+}
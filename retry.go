@@ -2,8 +2,12 @@ package rclonelib
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -17,6 +21,10 @@ type RetryConfig struct {
 	MaxDelay time.Duration
 	// Multiplier is the multiplier for exponential backoff (default: 2.0)
 	Multiplier float64
+	// RetryOnHTTPCodes lists HTTP status codes that make a failure retryable
+	// when they appear in rclone's error output, even if an ExitCodePolicy
+	// classified it as fatal
+	RetryOnHTTPCodes []int
 }
 
 // DefaultRetryConfig returns the default retry configuration
@@ -29,20 +37,69 @@ func DefaultRetryConfig() RetryConfig {
 	}
 }
 
-// ExecuteWithRetry executes an rclone command with retry logic and exponential backoff
-func (e *Executor) ExecuteWithRetry(transferID string, opts RcloneOptions, retryCfg RetryConfig) error {
-	if retryCfg.MaxAttempts <= 0 {
-		retryCfg.MaxAttempts = 1
+// withDefaults fills in unset fields. A zero MaxAttempts means a single
+// attempt.
+func (c RetryConfig) withDefaults() RetryConfig {
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 1
 	}
-	if retryCfg.InitialDelay <= 0 {
-		retryCfg.InitialDelay = 2 * time.Second
+	if c.InitialDelay <= 0 {
+		c.InitialDelay = 2 * time.Second
 	}
-	if retryCfg.MaxDelay <= 0 {
-		retryCfg.MaxDelay = 30 * time.Second
+	if c.MaxDelay <= 0 {
+		c.MaxDelay = 30 * time.Second
 	}
-	if retryCfg.Multiplier <= 0 {
-		retryCfg.Multiplier = 2.0
+	if c.Multiplier <= 0 {
+		c.Multiplier = 2.0
 	}
+	return c
+}
+
+// delay returns the backoff before retry n (1 for the first retry)
+func (c RetryConfig) delay(n int) time.Duration {
+	d := float64(c.InitialDelay) * math.Pow(c.Multiplier, float64(n-1))
+	return time.Duration(math.Min(d, float64(c.MaxDelay)))
+}
+
+// WithRetryOn adds HTTP status codes to RetryOnHTTPCodes
+func (c *RetryConfig) WithRetryOn(codes ...int) *RetryConfig {
+	c.RetryOnHTTPCodes = append(c.RetryOnHTTPCodes, codes...)
+	return c
+}
+
+// WithRetryOnRateLimit retries on 429 Too Many Requests and 503 Service
+// Unavailable, the codes most backends use for throttling
+func (c *RetryConfig) WithRetryOnRateLimit() *RetryConfig {
+	return c.WithRetryOn(http.StatusTooManyRequests, http.StatusServiceUnavailable)
+}
+
+// hasHTTPCode reports whether msg mentions any of codes as a standalone
+// number, so 429 matches "HTTP 429" but not "14290 bytes"
+func hasHTTPCode(msg string, codes []int) bool {
+	for _, code := range codes {
+		s := strconv.Itoa(code)
+		for rest, offset := msg, 0; ; {
+			i := strings.Index(rest, s)
+			if i < 0 {
+				break
+			}
+			start, end := offset+i, offset+i+len(s)
+			if (start == 0 || !isDigit(msg[start-1])) && (end == len(msg) || !isDigit(msg[end])) {
+				return true
+			}
+			rest, offset = msg[end:], end
+		}
+	}
+	return false
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// ExecuteWithRetry executes an rclone command with retry logic and exponential backoff
+func (e *Executor) ExecuteWithRetry(transferID string, opts RcloneOptions, retryCfg RetryConfig) error {
+	retryCfg = retryCfg.withDefaults()
 
 	ctx := opts.Context
 	if ctx == nil {
@@ -63,7 +120,8 @@ func (e *Executor) ExecuteWithRetry(transferID string, opts RcloneOptions, retry
 		default:
 		}
 
-		// Execute the transfer
+		// Execute the transfer, discarding throttling seen on earlier attempts
+		e.manager.takeRateLimit(transferID)
 		err := e.Execute(transferID, opts)
 		if err == nil {
 			return nil // Success
@@ -71,16 +129,40 @@ func (e *Executor) ExecuteWithRetry(transferID string, opts RcloneOptions, retry
 
 		lastErr = err
 
+		// Space won't free itself up between attempts
+		var spaceErr *ErrNoSpaceLeft
+		if errors.As(err, &spaceErr) {
+			return err
+		}
+
+		// Execute only classifies errors when an ExitCodePolicy said how
+		// to, so respect a fatal verdict unless the caller asked to retry
+		// on one of the HTTP codes rclone reported.
+		var classified *ClassifiedError
+		if errors.As(err, &classified) && !classified.Retryable && !hasHTTPCode(err.Error(), retryCfg.RetryOnHTTPCodes) {
+			return err
+		}
+
 		// Don't sleep after last attempt
 		if attempt == retryCfg.MaxAttempts {
 			break
 		}
 
+		// If the remote throttled this attempt and said how long to back
+		// off, honour that instead of our own schedule.
+		wait := delay
+		if rl := e.manager.takeRateLimit(transferID); rl != nil && rl.RetryAfter > 0 {
+			wait = rl.RetryAfter
+		}
+		if e.hooks.BeforeRetry != nil {
+			e.hooks.BeforeRetry(transferID, attempt+1, wait)
+		}
+
 		// Calculate next delay with exponential backoff
 		select {
 		case <-ctx.Done():
 			return fmt.Errorf("context cancelled after %d attempts: %w", attempt, lastErr)
-		case <-time.After(delay):
+		case <-time.After(wait):
 			delay = time.Duration(math.Min(
 				float64(delay)*retryCfg.Multiplier,
 				float64(retryCfg.MaxDelay),
@@ -0,0 +1,201 @@
+package rclonelib
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ServeProtocol is a protocol `rclone serve` can expose a remote over
+type ServeProtocol string
+
+const (
+	// ServeHTTP serves a read-only directory listing over HTTP
+	ServeHTTP ServeProtocol = "http"
+	// ServeWebDAV serves the remote over WebDAV
+	ServeWebDAV ServeProtocol = "webdav"
+	// ServeS3 serves the remote as an S3-compatible endpoint
+	ServeS3 ServeProtocol = "s3"
+)
+
+// defaultServeAddr is rclone's own default listen address for HTTP-based
+// serve commands
+const defaultServeAddr = "127.0.0.1:8080"
+
+// ServeServer runs `rclone serve` in the background
+type ServeServer struct {
+	Protocol ServeProtocol
+	Remote   string
+	// Addr is the address the server listens on ("host:port")
+	Addr string
+	// Flags are additional flags to pass to rclone
+	Flags []string
+	// Env sets environment variables for rclone, overriding inherited values
+	// with the same name. Secrets such as RCLONE_PASS belong here rather than
+	// in Flags, where the process list would show them.
+	Env map[string]string
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+	stderr bytes.Buffer
+}
+
+// ServeS3Options configures NewS3Server
+type ServeS3Options struct {
+	// Port to listen on at 127.0.0.1; ignored when Addr is set
+	Port int
+	// Addr is the full listen address (default: 127.0.0.1:8080)
+	Addr string
+	// AuthKey and AuthSecret are the access key ID and secret clients must
+	// use. When empty the server accepts anonymous requests.
+	AuthKey    string
+	AuthSecret string
+}
+
+// NewServeServer returns a server exposing remote over protocol at addr.
+// Call Start to run it.
+func NewServeServer(protocol ServeProtocol, remote, addr string, flags ...string) *ServeServer {
+	if addr == "" {
+		addr = defaultServeAddr
+	}
+	return &ServeServer{Protocol: protocol, Remote: remote, Addr: addr, Flags: flags}
+}
+
+// NewS3Server returns a server that exposes remote (which may be a local
+// path) as an S3-compatible endpoint using `rclone serve s3`. Buckets are
+// the top-level directories of remote. Handy for tests that need S3 without
+// AWS credentials. The access key and secret are handed to rclone as
+// RCLONE_AUTH_KEY rather than --auth-key, so they don't show up in the
+// process list.
+func NewS3Server(remote string, opts ServeS3Options) *ServeServer {
+	addr := opts.Addr
+	if addr == "" && opts.Port > 0 {
+		addr = "127.0.0.1:" + strconv.Itoa(opts.Port)
+	}
+
+	server := NewServeServer(ServeS3, remote, addr)
+	if opts.AuthKey != "" || opts.AuthSecret != "" {
+		server.Env = map[string]string{"RCLONE_AUTH_KEY": opts.AuthKey + "," + opts.AuthSecret}
+	}
+	return server
+}
+
+// URL returns the base URL of the server
+func (s *ServeServer) URL() string {
+	return "http://" + s.Addr
+}
+
+// Start launches rclone in the background. The server runs until Stop is
+// called or ctx is cancelled.
+func (s *ServeServer) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.done != nil {
+		return errors.New("server already started")
+	}
+	if s.Remote == "" {
+		return &ValidationError{Field: "remote", Message: "remote cannot be empty"}
+	}
+
+	args := []string{"serve", string(s.Protocol), s.Remote, "--addr", s.Addr}
+	args = append(args, s.Flags...)
+
+	runCtx, cancel := context.WithCancel(ctx)
+	cmd := exec.CommandContext(runCtx, "rclone", args...)
+	if len(s.Env) > 0 {
+		cmd.Env = buildEnv(s.Env, false)
+	}
+	cmd.Stderr = &s.stderr
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return fmt.Errorf("failed to start rclone serve %s: %w", s.Protocol, err)
+	}
+
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go func() {
+		err := cmd.Wait()
+		s.mu.Lock()
+		s.err = err
+		s.mu.Unlock()
+		close(s.done)
+	}()
+
+	return nil
+}
+
+// WaitReady polls the server until it answers HTTP requests, returning an
+// error if rclone exits first or ctx is done
+func (s *ServeServer) WaitReady(ctx context.Context) error {
+	s.mu.Lock()
+	done := s.done
+	s.mu.Unlock()
+	if done == nil {
+		return errors.New("server not started")
+	}
+
+	client := &http.Client{Timeout: time.Second}
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL(), nil)
+		if err != nil {
+			return err
+		}
+		if resp, err := client.Do(req); err == nil {
+			resp.Body.Close()
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-done:
+			return fmt.Errorf("rclone serve %s exited: %w", s.Protocol, s.exitErr())
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop shuts the server down and waits for rclone to exit
+func (s *ServeServer) Stop() error {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.mu.Unlock()
+	if done == nil {
+		return nil
+	}
+
+	cancel()
+	<-done
+	return nil
+}
+
+// exitErr describes why rclone exited, including its stderr
+func (s *ServeServer) exitErr() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg := strings.Join(strings.Fields(s.stderr.String()), " ")
+	switch {
+	case s.err != nil && msg != "":
+		return fmt.Errorf("%w: %s", s.err, msg)
+	case s.err != nil:
+		return s.err
+	case msg != "":
+		return errors.New(msg)
+	default:
+		return errors.New("exited without error")
+	}
+}
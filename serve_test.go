@@ -0,0 +1,23 @@
+package rclonelib
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewS3Server_AuthKeyNotOnCommandLine(t *testing.T) {
+	s := NewS3Server("/data", ServeS3Options{Port: 9000, AuthKey: "key", AuthSecret: "secret"})
+	if s.Addr != "127.0.0.1:9000" {
+		t.Errorf("Addr = %q", s.Addr)
+	}
+	if strings.Contains(strings.Join(s.Flags, " "), "secret") {
+		t.Errorf("secret on the command line: %v", s.Flags)
+	}
+	if s.Env["RCLONE_AUTH_KEY"] != "key,secret" {
+		t.Errorf("Env = %v, want RCLONE_AUTH_KEY", s.Env)
+	}
+
+	if anon := NewS3Server("/data", ServeS3Options{}); anon.Env != nil {
+		t.Errorf("anonymous server Env = %v", anon.Env)
+	}
+}
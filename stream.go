@@ -0,0 +1,177 @@
+package rclonelib
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StreamOptions configures StreamToRemote
+type StreamOptions struct {
+	// Size is the number of bytes that will be streamed, if known. Passing it
+	// lets rclone report progress and pick an efficient upload strategy.
+	Size int64
+	// Checksum is the expected MD5 of the data as a hex string. When set,
+	// StreamToRemote fails if the streamed data doesn't match.
+	Checksum string
+	// ContentType is sent as the Content-Type of the uploaded object on
+	// backends that store one (S3, GCS, Azure, WebDAV, ...)
+	ContentType string
+}
+
+// StreamResult describes the data written by StreamToRemote
+type StreamResult struct {
+	BytesWritten int64
+	// Hash is the MD5 of the streamed data as a hex string
+	Hash string
+}
+
+// StreamToRemote uploads everything read from r to dst/filename using
+// `rclone rcat`, without staging it on disk. It's the way to upload data the
+// caller generates in memory.
+//
+// It returns a StreamResult alongside the error, rather than just an error,
+// so callers get the byte count and hash without reading r twice. If
+// opts.Checksum doesn't match, the uploaded object is deleted again and the
+// result describes what was streamed.
+func StreamToRemote(ctx context.Context, r io.Reader, dst string, filename string, opts StreamOptions) (*StreamResult, error) {
+	if dst == "" {
+		return nil, &ValidationError{Field: "destination", Message: "destination cannot be empty"}
+	}
+	if filename == "" {
+		return nil, &ValidationError{Field: "filename", Message: "filename cannot be empty"}
+	}
+
+	target := childPath(dst, filename)
+	args := []string{"rcat", target}
+	if opts.Size > 0 {
+		args = append(args, "--size", strconv.FormatInt(opts.Size, 10))
+	}
+	if opts.ContentType != "" {
+		args = append(args, "--header-upload", "Content-Type: "+opts.ContentType)
+	}
+
+	hash := md5.New()
+	counter := &countingReader{r: io.TeeReader(r, hash)}
+
+	if _, err := runRcloneInput(ctx, counter, nil, args...); err != nil {
+		return nil, fmt.Errorf("failed to stream to %s: %w", dst, err)
+	}
+
+	result := &StreamResult{
+		BytesWritten: counter.n,
+		Hash:         hex.EncodeToString(hash.Sum(nil)),
+	}
+
+	if opts.Checksum != "" && !strings.EqualFold(opts.Checksum, result.Hash) {
+		err := fmt.Errorf("checksum mismatch streaming to %s: expected %s, got %s",
+			dst, opts.Checksum, result.Hash)
+		// Don't leave corrupt data where the caller expects a good copy
+		if _, delErr := runRclone(context.WithoutCancel(ctx), "deletefile", target); delErr != nil {
+			err = fmt.Errorf("%w (and failed to delete it: %v)", err, delErr)
+		}
+		return result, err
+	}
+
+	return result, nil
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// progressInterval is the minimum time between ProgressReader callbacks
+const progressInterval = 100 * time.Millisecond
+
+// ProgressReader wraps a reader and reports the running total of bytes read
+// to a callback, at most every 100ms and once more at EOF
+type ProgressReader struct {
+	r        io.Reader
+	progress func(n int64)
+	n        int64
+	last     time.Time
+}
+
+// NewProgressReader returns a ProgressReader reading from r. progress may be
+// nil.
+func NewProgressReader(r io.Reader, progress func(n int64)) *ProgressReader {
+	return &ProgressReader{r: r, progress: progress}
+}
+
+// Read implements io.Reader
+func (p *ProgressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.n += int64(n)
+
+	if p.progress != nil {
+		if now := time.Now(); err == io.EOF || now.Sub(p.last) >= progressInterval {
+			p.last = now
+			p.progress(p.n)
+		}
+	}
+	return n, err
+}
+
+// BytesRead returns the number of bytes read so far
+func (p *ProgressReader) BytesRead() int64 {
+	return p.n
+}
+
+// StreamFromRemote writes the contents of the remote file at path to w using
+// `rclone cat`, without staging it on disk. progress, if not nil, is called
+// periodically with the number of bytes copied so far.
+func StreamFromRemote(ctx context.Context, path string, w io.Writer, progress func(n int64)) error {
+	if path == "" {
+		return &ValidationError{Field: "path", Message: "path cannot be empty"}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cmd := exec.CommandContext(streamCtx, "rclone", "cat", path)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start rclone: %w", err)
+	}
+
+	if _, err := io.Copy(w, NewProgressReader(stdout, progress)); err != nil {
+		// Stop rclone rather than downloading the rest for nothing, and
+		// drain the pipe so Wait isn't left blocked on a full buffer.
+		cancel()
+		io.Copy(io.Discard, stdout)
+		cmd.Wait()
+		return fmt.Errorf("failed to write stream from %s: %w", path, err)
+	}
+
+	if err := cmd.Wait(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, strings.Join(strings.Fields(msg), " "))
+		}
+		return fmt.Errorf("failed to stream from %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package rclonelib
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// failingWriter rejects every write
+type failingWriter struct{ err error }
+
+func (f failingWriter) Write(p []byte) (int, error) { return 0, f.err }
+
+func TestStreamFromRemote_StopsOnWriteError(t *testing.T) {
+	// A remote file that never ends: only cancelling rclone can stop it.
+	fakeRclone(t, "exec yes\n")
+	errWrite := errors.New("disk full")
+
+	done := make(chan error, 1)
+	go func() {
+		done <- StreamFromRemote(context.Background(), "remote:big.bin", failingWriter{errWrite}, nil)
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, errWrite) {
+			t.Errorf("expected write error, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("StreamFromRemote kept reading after the writer failed")
+	}
+}
+
+func TestStreamToRemote_DeletesOnChecksumMismatch(t *testing.T) {
+	dir := t.TempDir()
+	fakeRclone(t, `case "$1" in
+rcat) cat > "`+dir+`/uploaded" ;;
+deletefile) echo "$2" > "`+dir+`/deleted" ;;
+esac
+`)
+
+	ctx := context.Background()
+	result, err := StreamToRemote(ctx, strings.NewReader("hello"), "remote:dir", "a.txt",
+		StreamOptions{Checksum: "5d41402abc4b2a76b9719d911017c592"})
+	if err != nil || result.BytesWritten != 5 {
+		t.Fatalf("matching checksum: %+v, %v", result, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "deleted")); err == nil {
+		t.Fatal("object deleted despite a matching checksum")
+	}
+
+	_, err = StreamToRemote(ctx, strings.NewReader("hellO"), "remote:dir", "a.txt",
+		StreamOptions{Checksum: "5d41402abc4b2a76b9719d911017c592"})
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	deleted, _ := os.ReadFile(filepath.Join(dir, "deleted"))
+	if strings.TrimSpace(string(deleted)) != "remote:dir/a.txt" {
+		t.Errorf("deleted %q, want remote:dir/a.txt", deleted)
+	}
+}
@@ -0,0 +1,88 @@
+package rclonelib
+
+import (
+	"fmt"
+	"time"
+)
+
+// statusLabel is the short upper-case name of a status used in the UI and
+// in FormatSummary
+func statusLabel(s Status) string {
+	switch s {
+	case StatusPending:
+		return "PENDING"
+	case StatusInProgress:
+		return "ACTIVE"
+	case StatusCompleted:
+		return "DONE"
+	case StatusFailed:
+		return "FAILED"
+	}
+	return string(s)
+}
+
+// FormatSummary returns a one-line summary of the transfer for logs and
+// plain-text progress output, e.g.
+//
+//	[ACTIVE] /data/a.iso -> remote:backup | 42% | 1.2 MiB/s | ETA 30s | 10.0 MiB / 24.0 MiB
+//
+// Unknown values are shown as "-".
+func (t *Transfer) FormatSummary() string {
+	f := t.summaryFields()
+	return fmt.Sprintf("[%s] %s -> %s | %s | %s | ETA %s | %s / %s",
+		statusLabel(t.Status), t.Source, t.Destination, f.percent,
+		f.speed, f.eta, f.copied, f.total)
+}
+
+// summaryFields are a transfer's figures formatted for display. FormatSummary
+// and the terminal UI both use them so the two can't disagree.
+type summaryFields struct {
+	percent string
+	speed   string
+	eta     string
+	copied  string
+	total   string
+}
+
+// summaryFields formats the transfer's progress, speed, ETA and sizes, with
+// "-" for anything unknown
+func (t *Transfer) summaryFields() summaryFields {
+	f := summaryFields{
+		percent: "-",
+		speed:   FormatSize(int64(t.currentSpeed())) + "/s",
+		eta:     "-",
+		copied:  FormatSize(t.BytesCopied),
+		total:   "-",
+	}
+	if p := t.PercentDone(); p >= 0 {
+		f.percent = fmt.Sprintf("%.0f%%", p)
+	}
+	if d, ok := t.eta(); ok {
+		f.eta = d.String()
+	}
+	if t.BytesTotal > 0 {
+		f.total = FormatSize(t.BytesTotal)
+	}
+	return f
+}
+
+// eta returns the time left as reported by rclone or, failing that,
+// estimated from the average speed so far, rounded to the second. ok is
+// false when there isn't enough information.
+func (t *Transfer) eta() (time.Duration, bool) {
+	if t.Status == StatusCompleted {
+		return 0, true
+	}
+	if t.IsActive() && t.ETA > 0 {
+		return t.ETA, true
+	}
+	speed := t.Speed()
+	if !t.IsActive() || speed <= 0 || t.BytesTotal <= 0 {
+		return 0, false
+	}
+	remaining := t.BytesTotal - t.BytesCopied
+	if remaining < 0 {
+		remaining = 0
+	}
+	return time.Duration(float64(remaining) / speed * float64(time.Second)).Round(time.Second), true
+}
@@ -0,0 +1,53 @@
+package rclonelib
+
+import "time"
+
+// TransferTemplate captures settings shared by many transfers so each one
+// only has to supply its source and destination
+type TransferTemplate struct {
+	// Command is the rclone command to run (default: copy)
+	Command RcloneCommand
+	// CommonFlags are converted to rclone flags, or environment variables
+	// with UseEnv, for every transfer
+	CommonFlags CommonFlags
+	// RetryConfig is carried alongside for use with ExecuteWithRetry; it
+	// doesn't affect the generated RcloneOptions
+	RetryConfig RetryConfig
+	// StatsInterval is how often rclone reports progress (default: 500ms)
+	StatsInterval time.Duration
+}
+
+// Apply returns RcloneOptions for a transfer from src to dst with the
+// template's settings
+func (tt TransferTemplate) Apply(src, dst string) RcloneOptions {
+	cmd := tt.Command
+	if cmd == "" {
+		cmd = RcloneCopy
+	}
+
+	statsInterval := "500ms"
+	if tt.StatsInterval > 0 {
+		statsInterval = tt.StatsInterval.String()
+	}
+
+	opts := RcloneOptions{
+		Command:       cmd,
+		Source:        src,
+		Destination:   dst,
+		Flags:         []string{},
+		StatsInterval: statsInterval,
+	}
+	Common(tt.CommonFlags)(&opts)
+	return opts
+}
+
+// WithOverride returns RcloneOptions with the template's settings and then
+// opts applied on top. The source and destination are expected to come from
+// opts.
+func (tt TransferTemplate) WithOverride(opts ...TransferOption) RcloneOptions {
+	result := tt.Apply("", "")
+	for _, opt := range opts {
+		opt(&result)
+	}
+	return result
+}
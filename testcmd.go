@@ -0,0 +1,253 @@
+package rclonelib
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// speedTestSize is the size of the payload TestRemoteSpeed uploads and
+// downloads
+const speedTestSize = 4 * 1024 * 1024
+
+// SpeedTestResult holds the throughput measured against a remote
+type SpeedTestResult struct {
+	UploadMBps   float64
+	DownloadMBps float64
+	// Latency is the round trip of a single metadata request, including
+	// rclone's process start-up
+	Latency time.Duration
+}
+
+// MemoryTestResult holds the output of `rclone test memory`
+type MemoryTestResult struct {
+	Objects        int
+	Bytes          int64
+	BytesPerObject float64
+}
+
+// TestRemoteSpeed measures upload and download throughput to remote by
+// streaming a 4 MiB payload up with `rclone rcat`, reading it back with
+// `rclone cat` and timing a stat of it. The test file is removed afterwards.
+// remote may include a directory ("s3:bucket/tmp") to write into.
+func TestRemoteSpeed(ctx context.Context, remote string) (*SpeedTestResult, error) {
+	if remote == "" {
+		return nil, &ValidationError{Field: "remote", Message: "remote cannot be empty"}
+	}
+
+	payload := make([]byte, speedTestSize)
+	if _, err := rand.Read(payload); err != nil {
+		return nil, fmt.Errorf("failed to generate test data: %w", err)
+	}
+
+	target := childPath(remote, fmt.Sprintf(".rclonelib-speedtest-%d", time.Now().UnixNano()))
+	defer runRclone(context.WithoutCancel(ctx), "deletefile", target)
+
+	result := &SpeedTestResult{}
+
+	// Each step starts a fresh rclone process. Time one that doesn't touch
+	// the remote and take it off every measurement, or start-up would
+	// dominate the latency of a nearby remote.
+	overhead := rcloneStartupTime(ctx)
+
+	start := time.Now()
+	if _, err := runRcloneInput(ctx, bytes.NewReader(payload), nil, "rcat", target); err != nil {
+		return nil, fmt.Errorf("speed test upload failed: %w", err)
+	}
+	result.UploadMBps = megabytesPerSecond(speedTestSize, sinceExcluding(start, overhead))
+
+	start = time.Now()
+	if _, err := runRclone(ctx, "lsjson", "--stat", target); err != nil {
+		return nil, fmt.Errorf("speed test stat failed: %w", err)
+	}
+	result.Latency = sinceExcluding(start, overhead)
+
+	start = time.Now()
+	if _, err := runRclone(ctx, "cat", target); err != nil {
+		return nil, fmt.Errorf("speed test download failed: %w", err)
+	}
+	result.DownloadMBps = megabytesPerSecond(speedTestSize, sinceExcluding(start, overhead))
+
+	return result, nil
+}
+
+// rcloneStartupTime measures how long rclone takes to start and exit without
+// doing any work. It returns 0 if rclone can't be run.
+func rcloneStartupTime(ctx context.Context) time.Duration {
+	start := time.Now()
+	if _, err := runRclone(ctx, "version", "--check=false"); err != nil {
+		return 0
+	}
+	return time.Since(start)
+}
+
+// sinceExcluding returns the time elapsed since start less overhead, never
+// less than a millisecond
+func sinceExcluding(start time.Time, overhead time.Duration) time.Duration {
+	d := time.Since(start) - overhead
+	if d < time.Millisecond {
+		return time.Millisecond
+	}
+	return d
+}
+
+// megabytesPerSecond converts a byte count and duration to MiB/s
+func megabytesPerSecond(n int64, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(n) / (1024 * 1024) / d.Seconds()
+}
+
+// memoryRegex matches the summary line of `rclone test memory`:
+// "1000 objects took 123456 bytes, 123.5 bytes/object"
+var memoryRegex = regexp.MustCompile(`(\d+) objects took (\d+) bytes, ([0-9.]+) bytes/object`)
+
+// TestRemoteMemory reports how much memory rclone uses per object when
+// listing remote, using `rclone test memory`
+func TestRemoteMemory(ctx context.Context, remote string) (*MemoryTestResult, error) {
+	if remote == "" {
+		return nil, &ValidationError{Field: "remote", Message: "remote cannot be empty"}
+	}
+
+	// rclone logs the result rather than printing it, so it arrives on stderr.
+	output, err := runRcloneCombined(ctx, "test", "memory", remote)
+	if err != nil {
+		return nil, fmt.Errorf("memory test failed: %w", err)
+	}
+
+	matches := memoryRegex.FindStringSubmatch(string(output))
+	if matches == nil {
+		return nil, fmt.Errorf("failed to parse memory test output")
+	}
+
+	objects, _ := strconv.Atoi(matches[1])
+	total, _ := strconv.ParseInt(matches[2], 10, 64)
+	perObject, _ := strconv.ParseFloat(matches[3], 64)
+
+	return &MemoryTestResult{Objects: objects, Bytes: total, BytesPerObject: perObject}, nil
+}
+
+// TestRemoteNormalization probes how remote handles Unicode normalization of
+// file names using `rclone test info --check-normalization`. It returns the
+// reported capabilities as "key = value" lines, e.g.
+// "canReadUnnormalized = true".
+func TestRemoteNormalization(ctx context.Context, remote string) ([]string, error) {
+	if remote == "" {
+		return nil, &ValidationError{Field: "remote", Message: "remote cannot be empty"}
+	}
+
+	output, err := runRclone(ctx, "test", "info", "--check-normalization", remote)
+	if err != nil {
+		return nil, fmt.Errorf("normalization test failed: %w", err)
+	}
+
+	var results []string
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.Contains(line, "ormalized = ") {
+			results = append(results, line)
+		}
+	}
+
+	return results, nil
+}
+
+// MakeFilesOptions configures MakeTestFiles. Zero values use rclone's
+// defaults.
+type MakeFilesOptions struct {
+	// Count is the total number of files to create (--files, default 1000)
+	Count int
+	// MinSize and MaxSize bound each file's size in bytes (--min-file-size,
+	// --max-file-size, default 0 to 100)
+	MinSize int64
+	MaxSize int64
+	// Seed makes the generated hierarchy reproducible (--seed, default 1)
+	Seed int64
+	// Depth is the maximum directory depth (--max-depth, default 10)
+	Depth int
+	// Files is the average number of files per directory
+	// (--files-per-directory, default 10)
+	Files int
+}
+
+// args converts the options to `rclone test makefiles` flags
+func (o MakeFilesOptions) args() []string {
+	var args []string
+	if o.Count > 0 {
+		args = append(args, "--files", strconv.Itoa(o.Count))
+	}
+	if o.MinSize > 0 {
+		args = append(args, "--min-file-size", strconv.FormatInt(o.MinSize, 10)+"B")
+	}
+	if o.MaxSize > 0 {
+		args = append(args, "--max-file-size", strconv.FormatInt(o.MaxSize, 10)+"B")
+	}
+	if o.Seed != 0 {
+		args = append(args, "--seed", strconv.FormatInt(o.Seed, 10))
+	}
+	if o.Depth > 0 {
+		args = append(args, "--max-depth", strconv.Itoa(o.Depth))
+	}
+	if o.Files > 0 {
+		args = append(args, "--files-per-directory", strconv.Itoa(o.Files))
+	}
+	return args
+}
+
+// MakeTestFiles fills dst with a random hierarchy of files using
+// `rclone test makefiles`. The same options and seed always produce the same
+// files. rclone can only generate files on local disk, so for a remote dst
+// they are generated in a temporary directory and copied up.
+func MakeTestFiles(ctx context.Context, dst string, opts MakeFilesOptions) error {
+	if dst == "" {
+		return &ValidationError{Field: "dst", Message: "destination cannot be empty"}
+	}
+	if opts.MinSize < 0 || opts.MaxSize < 0 {
+		return &ValidationError{Field: "size", Message: "file sizes cannot be negative"}
+	}
+	if opts.MaxSize > 0 && opts.MinSize > opts.MaxSize {
+		return &ValidationError{Field: "size", Message: "MinSize cannot exceed MaxSize"}
+	}
+
+	dir := dst
+	if IsRemotePath(dst) {
+		tmp, err := os.MkdirTemp("", "rclonelib-makefiles-*")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		defer os.RemoveAll(tmp)
+		dir = tmp
+	}
+
+	args := append([]string{"test", "makefiles"}, opts.args()...)
+	if _, err := runRclone(ctx, append(args, dir)...); err != nil {
+		return fmt.Errorf("failed to make test files: %w", err)
+	}
+
+	if dir != dst {
+		if _, err := runRclone(ctx, "copy", dir, dst); err != nil {
+			return fmt.Errorf("failed to copy test files to %s: %w", dst, err)
+		}
+	}
+	return nil
+}
+
+// CleanTestFiles removes dst and everything in it with Purge, so the root of
+// a remote or filesystem is refused with ErrDestructiveOperation
+func CleanTestFiles(ctx context.Context, dst string) error {
+	if dst == "" {
+		return &ValidationError{Field: "dst", Message: "destination cannot be empty"}
+	}
+
+	if err := Purge(ctx, dst, false); err != nil {
+		return fmt.Errorf("failed to clean test files: %w", err)
+	}
+	return nil
+}
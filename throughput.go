@@ -0,0 +1,91 @@
+package rclonelib
+
+import (
+	"sync"
+	"time"
+)
+
+// throughputRingSize is how many progress points each transfer keeps; at the
+// default 500ms stats interval that is a little over two minutes
+const throughputRingSize = 256
+
+// ThroughputSample is the transfer rate over the interval ending at At
+type ThroughputSample struct {
+	At             time.Time
+	BytesPerSecond float64
+}
+
+// throughputPoint is a single progress update
+type throughputPoint struct {
+	at    time.Time
+	bytes int64
+}
+
+// throughputRing is a fixed-size ring of progress points, safe for
+// concurrent use
+type throughputRing struct {
+	mu     sync.Mutex
+	points []throughputPoint
+	next   int
+	full   bool
+}
+
+func newThroughputRing(size int) *throughputRing {
+	return &throughputRing{points: make([]throughputPoint, size)}
+}
+
+// add records bytes copied at time at, overwriting the oldest point once
+// the ring is full
+func (r *throughputRing) add(at time.Time, bytes int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.points[r.next] = throughputPoint{at: at, bytes: bytes}
+	r.next = (r.next + 1) % len(r.points)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// ordered returns the recorded points, oldest first
+func (r *throughputRing) ordered() []throughputPoint {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]throughputPoint(nil), r.points[:r.next]...)
+	}
+	result := make([]throughputPoint, 0, len(r.points))
+	result = append(result, r.points[r.next:]...)
+	return append(result, r.points[:r.next]...)
+}
+
+// ThroughputSamples returns the transfer rate between consecutive progress
+// updates over the last d, oldest first. It is empty until at least two
+// updates have been received. Useful for sparklines and stall detection.
+func (t *Transfer) ThroughputSamples(d time.Duration) []ThroughputSample {
+	if t.samples == nil {
+		return nil
+	}
+
+	points := t.samples.ordered()
+	since := time.Now().Add(-d)
+
+	var samples []ThroughputSample
+	for i := 1; i < len(points); i++ {
+		prev, cur := points[i-1], points[i]
+		if cur.at.Before(since) {
+			continue
+		}
+		elapsed := cur.at.Sub(prev.at).Seconds()
+		if elapsed <= 0 {
+			continue
+		}
+		rate := float64(cur.bytes-prev.bytes) / elapsed
+		if rate < 0 {
+			rate = 0
+		}
+		samples = append(samples, ThroughputSample{At: cur.at, BytesPerSecond: rate})
+	}
+	return samples
+}
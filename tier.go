@@ -0,0 +1,78 @@
+package rclonelib
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// storageTiers lists the tiers `rclone settier` accepts for each backend type
+// that supports it
+var storageTiers = map[string][]string{
+	"s3": {
+		"STANDARD", "REDUCED_REDUNDANCY", "STANDARD_IA", "ONEZONE_IA",
+		"INTELLIGENT_TIERING", "GLACIER", "GLACIER_IR", "DEEP_ARCHIVE",
+	},
+	"azureblob":            {"Hot", "Cool", "Cold", "Archive"},
+	"google cloud storage": {"STANDARD", "NEARLINE", "COLDLINE", "ARCHIVE"},
+}
+
+// SetTier moves path (a file or directory) to the given storage tier using
+// `rclone settier`, e.g. "GLACIER" on S3 or "Archive" on Azure Blob
+func SetTier(ctx context.Context, tier, path string) error {
+	if tier == "" {
+		return &ValidationError{Field: "tier", Message: "tier cannot be empty"}
+	}
+	if path == "" {
+		return &ValidationError{Field: "path", Message: "path cannot be empty"}
+	}
+
+	if _, err := runRclone(ctx, "settier", tier, path); err != nil {
+		return fmt.Errorf("failed to set tier of %s to %s: %w", path, tier, err)
+	}
+	return nil
+}
+
+// GetTier returns the storage tier of a single object, as reported by
+// `rclone lsjson --stat`. Backends without tiers return an empty string.
+func GetTier(ctx context.Context, path string) (string, error) {
+	if path == "" {
+		return "", &ValidationError{Field: "path", Message: "path cannot be empty"}
+	}
+
+	output, err := runRclone(ctx, "lsjson", "--stat", "--no-mimetype", path)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	var item struct {
+		Tier string `json:"Tier"`
+	}
+	if err := json.Unmarshal(output, &item); err != nil {
+		return "", fmt.Errorf("failed to parse lsjson output: %w", err)
+	}
+	return item.Tier, nil
+}
+
+// ListTiers returns the storage tiers SetTier accepts for remote, based on
+// the remote's backend type. Backends that don't support tiers return a
+// validation error.
+func ListTiers(ctx context.Context, remote string) ([]string, error) {
+	name, _ := SplitRemotePath(remote)
+	if name == "" {
+		name = strings.TrimSuffix(remote, ":")
+	}
+
+	params, err := ConfigShow(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	backend := params["type"]
+	tiers, ok := storageTiers[backend]
+	if !ok {
+		return nil, &ValidationError{Field: "remote", Message: fmt.Sprintf("%s backend does not support storage tiers", backend)}
+	}
+	return append([]string(nil), tiers...), nil
+}
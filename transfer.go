@@ -2,6 +2,7 @@
 package rclonelib
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -18,6 +19,14 @@ const (
 	StatusFailed     Status = "failed"      // Transfer failed with an error
 )
 
+// Priority levels for AddWithPriority. Any int is allowed; higher values are
+// started first by NextPending.
+const (
+	PriorityHigh   = 10
+	PriorityNormal = 5
+	PriorityLow    = 1
+)
+
 // Transfer represents a single file transfer operation
 type Transfer struct {
 	ID          string
@@ -30,6 +39,33 @@ type Transfer struct {
 	StartTime   time.Time
 	EndTime     time.Time
 	Error       error
+	// CurrentSpeedBPS is the transfer speed in bytes per second as last
+	// reported by rclone, which averages over recent chunks. 0 if unknown.
+	CurrentSpeedBPS int64
+	// ETA is rclone's estimate of the time remaining, or 0 if unknown
+	ETA time.Duration
+	// Priority orders pending transfers for NextPending (default:
+	// PriorityNormal)
+	Priority int
+	// RateLimit is the most recent throttling reported by rclone, if any
+	RateLimit *RateLimitEvent
+	// Multipart tracks part-by-part progress of chunked uploads, when rclone
+	// reports it (see S3MultipartParser)
+	Multipart *MultipartProgress
+	// ReplayOf is the ID of the transfer this one was created from by
+	// Manager.Replay, if any
+	ReplayOf string
+	// ResetCount is the number of times the transfer has been re-queued with
+	// Manager.Reset
+	ResetCount int
+	// Annotations holds caller-defined metadata such as a user or job ID. Set
+	// it with Manager.Annotate; the map is replaced, never modified, so
+	// copies handed to notifiers stay consistent.
+	Annotations map[string]string
+
+	// samples records recent progress for ThroughputSamples. It is shared by
+	// copies of the transfer handed to notifiers.
+	samples *throughputRing
 }
 
 // Manager tracks multiple file transfers
@@ -37,20 +73,63 @@ type Manager struct {
 	mu        sync.RWMutex
 	transfers map[string]*Transfer
 	order     []string // Maintains insertion order
+
+	// changed is closed and replaced whenever a transfer changes, so waiters
+	// can block on it without polling.
+	changed  chan struct{}
+	draining bool
+
+	// failedSinceReset counts failures since the last ResetFailureCount and
+	// feeds HealthCheck.
+	failedSinceReset int
+	failureThreshold int
+
+	notifiers      []Notifier
+	subscribers    []chan TransferEvent
+	logSubscribers []chan LogEvent
+
+	// autoTune enables per-remote --transfers tuning in Execute; tuned caches
+	// the result for each remote. autoTuneMu only guards the fields: each
+	// remote's benchmark runs under its own sync.Once.
+	autoTuneMu sync.Mutex
+	autoTune   bool
+	tuned      map[string]*tunedTransfers
+
+	// tui is set once a terminal UI Model has been created for the manager
+	tui bool
+
+	// options records the RcloneOptions each transfer was last executed
+	// with, for Replay
+	options map[string]RcloneOptions
 }
 
+// ManagerOption configures a Manager at construction time
+type ManagerOption func(*Manager)
+
 // NewManager creates a new transfer manager
-func NewManager() *Manager {
-	return &Manager{
+func NewManager(opts ...ManagerOption) *Manager {
+	m := &Manager{
 		transfers: make(map[string]*Transfer),
 		order:     make([]string, 0),
+		changed:   make(chan struct{}),
+
+		failureThreshold: defaultFailureThreshold,
 	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
 }
 
-// Add adds a new transfer to the manager
+// Add adds a new transfer to the manager with PriorityNormal
 func (m *Manager) Add(id, source, destination string) *Transfer {
+	return m.AddWithPriority(id, source, destination, PriorityNormal)
+}
+
+// AddWithPriority adds a new transfer that NextPending will hand out ahead of
+// any pending transfer with a lower priority
+func (m *Manager) AddWithPriority(id, source, destination string, priority int) *Transfer {
 	m.mu.Lock()
-	defer m.mu.Unlock()
 
 	t := &Transfer{
 		ID:          id,
@@ -58,58 +137,197 @@ func (m *Manager) Add(id, source, destination string) *Transfer {
 		Destination: destination,
 		Status:      StatusPending,
 		Progress:    0,
+		Priority:    priority,
 	}
 
 	m.transfers[id] = t
 	m.order = append(m.order, id)
+	// Options recorded for an earlier transfer with this ID don't apply to
+	// the new one
+	delete(m.options, id)
+	m.broadcastLocked()
+
+	snapshot, notifiers := *t, m.notifiers
+	m.mu.Unlock()
+
+	notifyAll(notifiers, snapshot)
 	return t
 }
 
-// Start marks a transfer as in progress
-func (m *Manager) Start(id string) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	if t, exists := m.transfers[id]; exists {
+// Start marks a transfer as in progress. It returns ErrManagerDraining once
+// Drain has been called.
+func (m *Manager) Start(id string) error {
+	return m.update(id, func(t *Transfer) error {
+		if m.draining {
+			return ErrManagerDraining
+		}
 		t.Status = StatusInProgress
 		t.StartTime = time.Now()
+		return nil
+	})
+}
+
+// NextPending claims the highest-priority pending transfer, marking it in
+// progress, and returns it. Transfers of equal priority are handed out in
+// the order they were added. If nothing is pending it blocks until a
+// transfer is added or ctx is done. Once Drain has been called it returns
+// ErrManagerDraining.
+func (m *Manager) NextPending(ctx context.Context) (*Transfer, error) {
+	for {
+		m.mu.Lock()
+		if m.draining {
+			m.mu.Unlock()
+			return nil, ErrManagerDraining
+		}
+
+		var next *Transfer
+		for _, id := range m.order {
+			t, exists := m.transfers[id]
+			if !exists || t.Status != StatusPending {
+				continue
+			}
+			if next == nil || t.Priority > next.Priority {
+				next = t
+			}
+		}
+
+		if next != nil {
+			next.Status = StatusInProgress
+			next.StartTime = time.Now()
+			m.broadcastLocked()
+
+			snapshot, notifiers := *next, m.notifiers
+			m.mu.Unlock()
+
+			notifyAll(notifiers, snapshot)
+			return next, nil
+		}
+
+		changed := m.changed
+		m.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-changed:
+		}
 	}
 }
 
 // UpdateProgress updates the progress of a transfer
 func (m *Manager) UpdateProgress(id string, progress float64, bytesCopied, bytesTotal int64) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	if t, exists := m.transfers[id]; exists {
+	m.update(id, func(t *Transfer) error {
 		t.Progress = progress
 		t.BytesCopied = bytesCopied
 		t.BytesTotal = bytesTotal
-	}
+		if t.samples == nil {
+			t.samples = newThroughputRing(throughputRingSize)
+		}
+		t.samples.add(time.Now(), bytesCopied)
+		return nil
+	})
+}
+
+// UpdateStats updates the progress of a transfer along with the speed and
+// ETA rclone reported, e.g. from ParseTransferOutput
+func (m *Manager) UpdateStats(id string, update ProgressUpdate) {
+	m.update(id, func(t *Transfer) error {
+		t.Progress = update.Percent
+		t.BytesCopied = update.BytesCopied
+		t.BytesTotal = update.BytesTotal
+		t.CurrentSpeedBPS = int64(update.Speed)
+		t.ETA = update.ETA
+		if t.samples == nil {
+			t.samples = newThroughputRing(throughputRingSize)
+		}
+		t.samples.add(time.Now(), update.BytesCopied)
+		return nil
+	})
 }
 
 // Complete marks a transfer as completed successfully
 func (m *Manager) Complete(id string) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	if t, exists := m.transfers[id]; exists {
+	m.update(id, func(t *Transfer) error {
 		t.Status = StatusCompleted
 		t.Progress = 100
 		t.EndTime = time.Now()
-	}
+		return nil
+	})
 }
 
 // Fail marks a transfer as failed with an error
 func (m *Manager) Fail(id string, err error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	if t, exists := m.transfers[id]; exists {
+	m.update(id, func(t *Transfer) error {
 		t.Status = StatusFailed
 		t.EndTime = time.Now()
 		t.Error = err
+		m.failedSinceReset++
+		return nil
+	})
+}
+
+// Reset re-queues a completed or failed transfer, returning it to
+// StatusPending with its progress, timings and error cleared and ResetCount
+// incremented. Waiting NextPending calls pick it up. Resetting a pending
+// transfer is a no-op; a running one returns ErrTransferInProgress.
+func (m *Manager) Reset(id string) error {
+	if _, exists := m.Get(id); !exists {
+		return fmt.Errorf("%w: %s", ErrTransferNotFound, id)
 	}
+
+	return m.update(id, func(t *Transfer) error {
+		switch t.Status {
+		case StatusInProgress:
+			return fmt.Errorf("%w: %s", ErrTransferInProgress, id)
+		case StatusPending:
+			return nil
+		}
+		t.reset()
+		t.Status = StatusPending
+		t.ResetCount++
+		return nil
+	})
+}
+
+// reset clears the results of a previous run. The caller must hold the
+// manager's write lock.
+func (t *Transfer) reset() {
+	t.Progress = 0
+	t.BytesCopied = 0
+	t.BytesTotal = 0
+	t.StartTime = time.Time{}
+	t.EndTime = time.Time{}
+	t.Error = nil
+	t.CurrentSpeedBPS = 0
+	t.ETA = 0
+	t.RateLimit = nil
+	t.Multipart = nil
+	t.samples = nil
+}
+
+// update applies fn to the transfer with the given ID under the write lock.
+// If fn succeeds, waiters are woken and registered notifiers receive a copy
+// of the updated transfer once the lock has been released. Unknown IDs are
+// ignored.
+func (m *Manager) update(id string, fn func(t *Transfer) error) error {
+	m.mu.Lock()
+
+	t, exists := m.transfers[id]
+	if !exists {
+		m.mu.Unlock()
+		return nil
+	}
+	if err := fn(t); err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	m.broadcastLocked()
+
+	snapshot, notifiers := *t, m.notifiers
+	m.mu.Unlock()
+
+	notifyAll(notifiers, snapshot)
+	return nil
 }
 
 // Get retrieves a transfer by ID
@@ -121,6 +339,19 @@ func (m *Manager) Get(id string) (*Transfer, bool) {
 	return t, exists
 }
 
+// copyOf returns a copy of the transfer with the given ID, taken under the
+// read lock so it's safe to inspect while the transfer runs
+func (m *Manager) copyOf(id string) (Transfer, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	t, exists := m.transfers[id]
+	if !exists {
+		return Transfer{}, false
+	}
+	return *t, true
+}
+
 // GetAll returns all transfers in insertion order
 func (m *Manager) GetAll() []*Transfer {
 	m.mu.RLock()
@@ -155,6 +386,84 @@ func (m *Manager) Stats() (pending, inProgress, completed, failed int) {
 	return
 }
 
+// Clone returns a new Manager holding the same transfer definitions, in the
+// same order, all reset to StatusPending with progress, timing and errors
+// cleared. Configuration such as the failure threshold is copied; notifiers,
+// subscribers and the draining state are not. The clone shares no state with
+// the original.
+func (m *Manager) Clone() *Manager {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	clone := NewManager()
+	clone.failureThreshold = m.failureThreshold
+
+	for _, id := range m.order {
+		t, exists := m.transfers[id]
+		if !exists {
+			continue
+		}
+		if _, dup := clone.transfers[id]; !dup {
+			clone.order = append(clone.order, id)
+		}
+		clone.transfers[id] = &Transfer{
+			ID:          t.ID,
+			Source:      t.Source,
+			Destination: t.Destination,
+			Status:      StatusPending,
+			Priority:    t.Priority,
+			Annotations: t.Annotations,
+		}
+	}
+
+	return clone
+}
+
+// Drain stops the manager from starting new transfers and blocks until every
+// in-progress transfer has completed or failed. Pending transfers are left
+// queued. Once draining, Start returns ErrManagerDraining.
+func (m *Manager) Drain(ctx context.Context) error {
+	m.mu.Lock()
+	m.draining = true
+	m.mu.Unlock()
+
+	for {
+		m.mu.RLock()
+		active := 0
+		for _, t := range m.transfers {
+			if t.IsActive() {
+				active++
+			}
+		}
+		changed := m.changed
+		m.mu.RUnlock()
+
+		if active == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// Draining reports whether Drain has been called
+func (m *Manager) Draining() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.draining
+}
+
+// broadcastLocked wakes everything waiting on a status change. The caller
+// must hold the write lock.
+func (m *Manager) broadcastLocked() {
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
 // Duration returns the elapsed time for a transfer
 func (t *Transfer) Duration() time.Duration {
 	if t.StartTime.IsZero() {
@@ -166,6 +475,29 @@ func (t *Transfer) Duration() time.Duration {
 	return t.EndTime.Sub(t.StartTime)
 }
 
+// IsTerminal reports whether the transfer has finished, successfully or not
+func (t *Transfer) IsTerminal() bool {
+	return t.Status == StatusCompleted || t.Status == StatusFailed
+}
+
+// IsActive reports whether the transfer is currently running
+func (t *Transfer) IsActive() bool {
+	return t.Status == StatusInProgress
+}
+
+// PercentDone returns the completion percentage of a transfer. It prefers the
+// percentage reported by rclone, falls back to the byte counts when rclone
+// hasn't emitted a stats line yet, and returns -1 when neither is known.
+func (t *Transfer) PercentDone() float64 {
+	if t.Progress > 0 {
+		return t.Progress
+	}
+	if t.BytesTotal > 0 {
+		return float64(t.BytesCopied) / float64(t.BytesTotal) * 100
+	}
+	return -1
+}
+
 // FormattedBytes returns a human-readable byte size
 func FormattedBytes(bytes int64) string {
 	const unit = 1024
@@ -180,7 +512,32 @@ func FormattedBytes(bytes int64) string {
 	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
-// Speed calculates transfer speed in bytes per second
+// UseIEC selects the units used by FormatSize: binary IEC units (KiB, MiB;
+// powers of 1024) when true, the default, or decimal SI units (kB, MB;
+// powers of 1000) when false
+var UseIEC = true
+
+// FormatSize returns a human-readable size in the units selected by UseIEC,
+// e.g. "1.5 MiB" or "1.6 MB"
+func FormatSize(size int64) string {
+	unit, prefixes, suffix := int64(1024), "KMGTPE", "iB"
+	if !UseIEC {
+		unit, prefixes, suffix = 1000, "kMGTPE", "B"
+	}
+
+	if size < unit && size > -unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := unit, 0
+	for n := size / unit; n >= unit || n <= -unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %c%s", float64(size)/float64(div), prefixes[exp], suffix)
+}
+
+// Speed calculates the average transfer speed in bytes per second since the
+// transfer started. See CurrentSpeedBPS for the speed rclone reports.
 func (t *Transfer) Speed() float64 {
 	if t.StartTime.IsZero() {
 		return 0
@@ -192,9 +549,19 @@ func (t *Transfer) Speed() float64 {
 	return float64(t.BytesCopied) / elapsed
 }
 
-// FormattedSpeed returns human-readable transfer speed
+// currentSpeed returns the speed rclone reported, falling back to the
+// average speed when it hasn't reported one
+func (t *Transfer) currentSpeed() float64 {
+	if t.CurrentSpeedBPS > 0 {
+		return float64(t.CurrentSpeedBPS)
+	}
+	return t.Speed()
+}
+
+// FormattedSpeed returns human-readable transfer speed, preferring the
+// speed rclone reported
 func (t *Transfer) FormattedSpeed() string {
-	speed := t.Speed()
+	speed := t.currentSpeed()
 	if speed == 0 {
 		return "0 B/s"
 	}
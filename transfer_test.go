@@ -0,0 +1,385 @@
+package rclonelib
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+)
+
+func TestNextPending_HighPriorityFirst(t *testing.T) {
+	m := NewManager()
+	m.AddWithPriority("low", "a", "b", PriorityLow)
+	m.Add("normal", "a", "b")
+	m.AddWithPriority("high-1", "a", "b", PriorityHigh)
+	m.AddWithPriority("high-2", "a", "b", PriorityHigh)
+
+	ctx := context.Background()
+	want := []string{"high-1", "high-2", "normal", "low"}
+	for _, id := range want {
+		next, err := m.NextPending(ctx)
+		if err != nil {
+			t.Fatalf("NextPending: %v", err)
+		}
+		if next.ID != id {
+			t.Fatalf("expected %s, got %s", id, next.ID)
+		}
+		if next.Status != StatusInProgress {
+			t.Errorf("%s: expected status %s, got %s", id, StatusInProgress, next.Status)
+		}
+	}
+}
+
+func TestNextPending_WaitsForSlot(t *testing.T) {
+	m := NewManager()
+
+	got := make(chan string, 1)
+	go func() {
+		next, err := m.NextPending(context.Background())
+		if err != nil {
+			got <- err.Error()
+			return
+		}
+		got <- next.ID
+	}()
+
+	// Give the waiter a moment to block before anything is queued.
+	time.Sleep(10 * time.Millisecond)
+	m.AddWithPriority("high", "a", "b", PriorityHigh)
+
+	select {
+	case id := <-got:
+		if id != "high" {
+			t.Fatalf("expected high, got %s", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("NextPending did not wake up when a transfer was added")
+	}
+}
+
+func TestNextPending_ContextAndDrain(t *testing.T) {
+	m := NewManager()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.NextPending(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	m.Add("t1", "a", "b")
+	if err := m.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain: %v", err)
+	}
+	if _, err := m.NextPending(context.Background()); !errors.Is(err, ErrManagerDraining) {
+		t.Fatalf("expected ErrManagerDraining, got %v", err)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	defer func(prev bool) { UseIEC = prev }(UseIEC)
+
+	tests := []struct {
+		iec  bool
+		size int64
+		want string
+	}{
+		{true, 0, "0 B"},
+		{true, 1023, "1023 B"},
+		{true, 1024, "1.0 KiB"},
+		{true, 1536 * 1024, "1.5 MiB"},
+		{true, 5 << 40, "5.0 TiB"},
+		{false, 999, "999 B"},
+		{false, 1000, "1.0 kB"},
+		{false, 1_500_000, "1.5 MB"},
+		{false, 2_000_000_000, "2.0 GB"},
+	}
+
+	for _, tt := range tests {
+		UseIEC = tt.iec
+		if got := FormatSize(tt.size); got != tt.want {
+			t.Errorf("FormatSize(%d) with UseIEC=%v = %q, want %q", tt.size, tt.iec, got, tt.want)
+		}
+	}
+}
+
+func TestAnnotations_Persisted(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+
+	pm, err := NewPersistentManager(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pm.Add("t1", "/src", "remote:dst")
+	if err := pm.Annotate("t1", "job", "42"); err != nil {
+		t.Fatalf("Annotate: %v", err)
+	}
+	if err := pm.Annotate("missing", "job", "42"); !errors.Is(err, ErrTransferNotFound) {
+		t.Errorf("expected ErrTransferNotFound, got %v", err)
+	}
+
+	restored, err := NewPersistentManager(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := restored.GetAnnotation("t1", "job"); !ok || v != "42" {
+		t.Errorf("GetAnnotation = %q, %v; want 42", v, ok)
+	}
+}
+
+func TestPersistentManager_RoundTrip(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+
+	pm, err := NewPersistentManager(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pm.AddWithPriority("done", "/src/a", "remote:a", PriorityHigh)
+	pm.Add("failed", "/src/b", "remote:b")
+	pm.Add("running", "/src/c", "remote:c")
+	pm.Add("queued", "/src/d", "remote:d")
+
+	// Changes made through the embedded Manager, as an Executor or Pool
+	// makes them, are saved too.
+	m := pm.Manager
+	m.Start("done")
+	m.UpdateProgress("done", 100, 10, 10)
+	m.Complete("done")
+	m.Start("failed")
+	m.Fail("failed", errors.New("connection reset"))
+	m.Start("running")
+	m.UpdateProgress("running", 50, 5, 10)
+
+	restored, err := NewPersistentManager(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Status{
+		"done":    StatusCompleted,
+		"failed":  StatusFailed,
+		"running": StatusPending,
+		"queued":  StatusPending,
+	}
+	var ids []string
+	for _, tr := range restored.GetAll() {
+		ids = append(ids, tr.ID)
+		if tr.Status != want[tr.ID] {
+			t.Errorf("%s: status %s, want %s", tr.ID, tr.Status, want[tr.ID])
+		}
+	}
+	if strings.Join(ids, ",") != "done,failed,running,queued" {
+		t.Errorf("restored order = %v", ids)
+	}
+
+	if tr, _ := restored.Get("done"); tr.Priority != PriorityHigh || tr.BytesCopied != 10 {
+		t.Errorf("completed transfer not restored intact: %+v", tr)
+	}
+	if tr, _ := restored.Get("failed"); tr.Error == nil || tr.Error.Error() != "connection reset" {
+		t.Errorf("failed transfer error = %v", tr.Error)
+	}
+	// The interrupted transfer starts again from scratch.
+	if tr, _ := restored.Get("running"); tr.BytesCopied != 0 || !tr.StartTime.IsZero() {
+		t.Errorf("in-progress transfer not reset: %+v", tr)
+	}
+}
+
+func TestFormatSummary(t *testing.T) {
+	start := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	done := &Transfer{
+		Source:      "/data/a.iso",
+		Destination: "remote:backup",
+		Status:      StatusCompleted,
+		Progress:    100,
+		BytesTotal:  20 << 20,
+		BytesCopied: 20 << 20,
+		StartTime:   start,
+		EndTime:     start.Add(10 * time.Second),
+	}
+	want := "[DONE] /data/a.iso -> remote:backup | 100% | 2.0 MiB/s | ETA 0s | 20.0 MiB / 20.0 MiB"
+	if got := done.FormatSummary(); got != want {
+		t.Errorf("completed:\n got %q\nwant %q", got, want)
+	}
+
+	pending := &Transfer{Source: "a", Destination: "b", Status: StatusPending}
+	want = "[PENDING] a -> b | - | 0 B/s | ETA - | 0 B / -"
+	if got := pending.FormatSummary(); got != want {
+		t.Errorf("pending:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestRenderTransfer_MatchesFormatSummary(t *testing.T) {
+	m := NewModel(nil)
+	m.progress["t1"] = progress.New()
+
+	tr := &Transfer{
+		ID:              "t1",
+		Source:          "/data/a.iso",
+		Destination:     "remote:backup",
+		Status:          StatusInProgress,
+		BytesTotal:      20 << 20,
+		BytesCopied:     5 << 20,
+		CurrentSpeedBPS: 1 << 20,
+		ETA:             15 * time.Second,
+		StartTime:       time.Now(),
+	}
+	rendered := m.renderTransfer(tr)
+	summary := tr.FormatSummary()
+
+	for _, field := range []string{"25%", "1.0 MiB/s", "15s", "5.0 MiB", "20.0 MiB"} {
+		if !strings.Contains(summary, field) || !strings.Contains(rendered, field) {
+			t.Errorf("%q missing from summary %q or UI %q", field, summary, rendered)
+		}
+	}
+}
+
+func TestReset_RequeuesAndWakesNextPending(t *testing.T) {
+	m := NewManager()
+	m.Add("t1", "a", "b")
+	if _, err := m.NextPending(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Reset("t1"); !errors.Is(err, ErrTransferInProgress) {
+		t.Errorf("Reset while running: expected ErrTransferInProgress, got %v", err)
+	}
+	m.Fail("t1", errors.New("boom"))
+
+	got := make(chan *Transfer, 1)
+	go func() {
+		tr, _ := m.NextPending(context.Background())
+		got <- tr
+	}()
+
+	if err := m.Reset("t1"); err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+
+	select {
+	case tr := <-got:
+		if tr.ID != "t1" || tr.ResetCount != 1 || tr.Error != nil {
+			t.Errorf("unexpected transfer after reset: %+v", tr)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("NextPending did not pick up the reset transfer")
+	}
+}
+
+func TestReplay(t *testing.T) {
+	m := NewManager()
+	m.AddWithPriority("t1", "/src", "remote:dst", PriorityHigh)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	m.recordOptions("t1", RcloneOptions{Command: RcloneSync, Source: "/src", Destination: "remote:dst", Context: ctx})
+
+	if _, err := m.Replay("t1"); err == nil {
+		t.Error("expected error replaying a pending transfer")
+	}
+	m.Complete("t1")
+
+	first, err := m.Replay("t1")
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	second, _ := m.Replay("t1")
+	if first != "t1_replay_1" || second != "t1_replay_2" {
+		t.Errorf("replay IDs = %q, %q", first, second)
+	}
+
+	tr, _ := m.Get(first)
+	if tr.Status != StatusPending || tr.ReplayOf != "t1" || tr.Priority != PriorityHigh {
+		t.Errorf("unexpected replay transfer: %+v", tr)
+	}
+	if opts, ok := m.Options(first); !ok || opts.Command != RcloneSync || opts.Context != nil {
+		t.Errorf("options not carried over without context: %+v, %v", opts, ok)
+	}
+
+	// Re-adding an ID drops the options recorded for the old transfer.
+	m.Add(first, "/other", "remote:other")
+	if _, ok := m.Options(first); ok {
+		t.Error("options survived re-adding the transfer")
+	}
+}
+
+func TestPartitionAndGroupBy(t *testing.T) {
+	m := NewManager()
+	m.Add("a", "/src/a", "s3:bucket/a")
+	m.Add("b", "/src/b", "gdrive:b")
+	m.Add("c", "/src/c", "s3:bucket/c")
+	m.Start("b")
+
+	matching, rest := m.Partition(ByStatus(StatusPending))
+	if len(matching) != 2 || matching[0].ID != "a" || matching[1].ID != "c" {
+		t.Errorf("matching = %v", transferIDs(matching))
+	}
+	if len(rest) != 1 || rest[0].ID != "b" {
+		t.Errorf("rest = %v", transferIDs(rest))
+	}
+
+	groups := m.GroupBy(func(t *Transfer) string {
+		remote, _, _ := strings.Cut(t.Destination, ":")
+		return remote
+	})
+	if len(groups) != 2 || len(groups["s3"]) != 2 || len(groups["gdrive"]) != 1 {
+		t.Errorf("groups = %v", groups)
+	}
+}
+
+func transferIDs(transfers []*Transfer) []string {
+	ids := make([]string, len(transfers))
+	for i, t := range transfers {
+		ids[i] = t.ID
+	}
+	return ids
+}
+
+func TestTransferJSON_RoundTrip(t *testing.T) {
+	start := time.Date(2024, 1, 2, 15, 4, 5, 123456789, time.UTC)
+	orig := &Transfer{
+		ID:          "t1",
+		Source:      "/data/a.txt",
+		Destination: "s3:bucket/a.txt",
+		Status:      StatusFailed,
+		Progress:    42.5,
+		BytesTotal:  1000,
+		BytesCopied: 425,
+		StartTime:   start,
+		EndTime:     start.Add(time.Minute),
+		Error:       errors.New("connection reset"),
+		Priority:    PriorityHigh,
+		ResetCount:  2,
+		ReplayOf:    "t0",
+		Annotations: map[string]string{"user": "alice"},
+	}
+
+	data, err := orig.JSON()
+	if err != nil {
+		t.Fatalf("JSON: %v", err)
+	}
+	for _, want := range []string{`"status":"failed"`, `"error":"connection reset"`, `"start_time":"2024-01-02T15:04:05.123456789Z"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("JSON missing %s: %s", want, data)
+		}
+	}
+
+	// Notifiers get Transfer values, which must encode the same way.
+	byValue, err := json.Marshal(*orig)
+	if err != nil || string(byValue) != string(data) {
+		t.Errorf("value encoding = %s, %v; want %s", byValue, err, data)
+	}
+
+	var got Transfer
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if got.Error == nil || got.Error.Error() != orig.Error.Error() {
+		t.Errorf("Error = %v, want %v", got.Error, orig.Error)
+	}
+	got.Error, orig.Error = nil, nil
+	if !reflect.DeepEqual(&got, orig) {
+		t.Errorf("round trip mismatch:\n got %+v\nwant %+v", got, *orig)
+	}
+}
@@ -0,0 +1,87 @@
+package rclonelib
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// transferJSON is the JSON representation of a Transfer. Error is carried as
+// its message since arbitrary error values can't be round-tripped, and times
+// use encoding/json's RFC 3339 format with nanoseconds.
+type transferJSON struct {
+	ID           string            `json:"id"`
+	Source       string            `json:"source"`
+	Destination  string            `json:"destination"`
+	Status       Status            `json:"status"`
+	Progress     float64           `json:"progress"`
+	BytesTotal   int64             `json:"bytes_total"`
+	BytesCopied  int64             `json:"bytes_copied"`
+	StartTime    time.Time         `json:"start_time"`
+	EndTime      time.Time         `json:"end_time"`
+	ErrorMessage string            `json:"error,omitempty"`
+	Priority     int               `json:"priority,omitempty"`
+	ResetCount   int               `json:"reset_count,omitempty"`
+	ReplayOf     string            `json:"replay_of,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+}
+
+// JSON returns the transfer encoded as JSON, e.g. for an audit log or to
+// send its state over the network. Error is encoded as its message; speed,
+// ETA, rate limit, multipart and throughput details are left out.
+func (t *Transfer) JSON() ([]byte, error) {
+	return json.Marshal(t)
+}
+
+// MarshalJSON implements json.Marshaler; see JSON. It has a value receiver
+// so the copies handed to notifiers encode the same way as pointers.
+func (t Transfer) MarshalJSON() ([]byte, error) {
+	j := transferJSON{
+		ID:          t.ID,
+		Source:      t.Source,
+		Destination: t.Destination,
+		Status:      t.Status,
+		Progress:    t.Progress,
+		BytesTotal:  t.BytesTotal,
+		BytesCopied: t.BytesCopied,
+		StartTime:   t.StartTime,
+		EndTime:     t.EndTime,
+		Priority:    t.Priority,
+		ResetCount:  t.ResetCount,
+		ReplayOf:    t.ReplayOf,
+		Annotations: t.Annotations,
+	}
+	if t.Error != nil {
+		j.ErrorMessage = t.Error.Error()
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, decoding the format written by
+// JSON. A non-empty error message becomes an error with that text.
+func (t *Transfer) UnmarshalJSON(data []byte) error {
+	var j transferJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	*t = Transfer{
+		ID:          j.ID,
+		Source:      j.Source,
+		Destination: j.Destination,
+		Status:      j.Status,
+		Progress:    j.Progress,
+		BytesTotal:  j.BytesTotal,
+		BytesCopied: j.BytesCopied,
+		StartTime:   j.StartTime,
+		EndTime:     j.EndTime,
+		Priority:    j.Priority,
+		ResetCount:  j.ResetCount,
+		ReplayOf:    j.ReplayOf,
+		Annotations: j.Annotations,
+	}
+	if j.ErrorMessage != "" {
+		t.Error = errors.New(j.ErrorMessage)
+	}
+	return nil
+}
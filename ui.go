@@ -48,8 +48,12 @@ type Model struct {
 	done     bool
 }
 
-// NewModel creates a new transfer UI model
+// NewModel creates a new transfer UI model. While a model exists for a
+// manager, Execute drops --progress-terminal-title from its transfers.
 func NewModel(manager *Manager) Model {
+	if manager != nil {
+		manager.attachTUI()
+	}
 	return Model{
 		manager:  manager,
 		progress: make(map[string]progress.Model),
@@ -109,7 +113,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Update progress bars
 		cmds := make([]tea.Cmd, 0)
 		for _, t := range m.manager.GetAll() {
-			if t.Status == StatusInProgress {
+			if t.IsActive() {
 				if _, exists := m.progress[t.ID]; !exists {
 					prog := progress.New(
 						progress.WithDefaultGradient(),
@@ -198,20 +202,14 @@ func (m Model) renderTransfer(t *Transfer) string {
 	var b strings.Builder
 
 	// Status prefix
-	prefix := " "
+	prefix := fmt.Sprintf("%-9s", "["+statusLabel(t.Status)+"]")
 	style := pendingStyle
 	switch t.Status {
-	case StatusPending:
-		prefix = "[PENDING]"
-		style = pendingStyle
 	case StatusInProgress:
-		prefix = "[ACTIVE] "
 		style = inProgressStyle
 	case StatusCompleted:
-		prefix = "[DONE]   "
 		style = completedStyle
 	case StatusFailed:
-		prefix = "[FAILED] "
 		style = failedStyle
 	}
 
@@ -226,27 +224,27 @@ func (m Model) renderTransfer(t *Transfer) string {
 		dest = "..." + dest[len(dest)-27:]
 	}
 
-	statusLine := fmt.Sprintf("%s %s -> %s", prefix, filename, dest)
+	statusLine := fmt.Sprintf("%s %s%s -> %s", prefix, priorityIndicator(t.Priority), filename, dest)
 	b.WriteString(itemStyle.Render(style.Render(statusLine)))
 	b.WriteString("\n")
 
 	// Second line: progress bar (if in progress)
-	if t.Status == StatusInProgress {
+	if t.IsActive() {
 		if prog, exists := m.progress[t.ID]; exists {
 			// Show progress bar even if we don't have percentage yet
-			if t.Progress > 0 {
-				progressBar := prog.ViewAs(t.Progress / 100.0)
+			if percent := t.PercentDone(); percent >= 0 {
+				progressBar := prog.ViewAs(percent / 100.0)
 				b.WriteString(itemStyle.Render(progressBar))
 				b.WriteString("\n")
 
 				// Add stats if we have them
 				if t.BytesTotal > 0 {
-					stats := fmt.Sprintf("  %s / %s (%s) %.0f%%",
-						FormattedBytes(t.BytesCopied),
-						FormattedBytes(t.BytesTotal),
-						t.FormattedSpeed(),
-						t.Progress,
-					)
+					f := t.summaryFields()
+					stats := fmt.Sprintf("  %s / %s (%s) %s  ETA: %s",
+						f.copied, f.total, f.speed, f.percent, f.eta)
+					if t.IsMultipart() {
+						stats += fmt.Sprintf("  Part %d/%d", t.Multipart.CompletedParts, t.Multipart.TotalParts)
+					}
 					b.WriteString(itemStyle.Render(pendingStyle.Render(stats)))
 					b.WriteString("\n")
 				}
@@ -267,7 +265,7 @@ func (m Model) renderTransfer(t *Transfer) string {
 	}
 
 	// Duration (if completed or failed)
-	if t.Status == StatusCompleted || t.Status == StatusFailed {
+	if t.IsTerminal() {
 		duration := t.Duration()
 		timeMsg := fmt.Sprintf("  Completed in %v", duration.Round(time.Millisecond))
 		b.WriteString(itemStyle.Render(pendingStyle.Render(timeMsg)))
@@ -277,6 +275,32 @@ func (m Model) renderTransfer(t *Transfer) string {
 	return b.String()
 }
 
+// attachTUI records that a terminal UI is displaying the manager
+func (m *Manager) attachTUI() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tui = true
+}
+
+// tuiAttached reports whether a terminal UI is displaying the manager
+func (m *Manager) tuiAttached() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.tui
+}
+
+// priorityIndicator marks transfers queued above or below normal priority
+func priorityIndicator(priority int) string {
+	switch {
+	case priority >= PriorityHigh:
+		return "↑ "
+	case priority <= PriorityLow:
+		return "↓ "
+	default:
+		return ""
+	}
+}
+
 // Run starts the transfer UI
 func Run(manager *Manager) error {
 	p := tea.NewProgram(NewModel(manager))
@@ -2,6 +2,7 @@ package rclonelib
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -50,20 +51,35 @@ func ValidateRcloneVersion(minVersion string) error {
 	return nil
 }
 
-// ValidateSourcePath checks if source path exists
+// DefaultValidationTimeout bounds how long ValidateSourcePath waits for a
+// local path to be stat'ed before giving up
+var DefaultValidationTimeout = 5 * time.Second
+
+// ValidateSourcePath checks if source path exists. Local paths are checked
+// with DefaultValidationTimeout so a hung network mount can't block forever.
 func ValidateSourcePath(path string) error {
+	return ValidateSourcePathWithTimeout(path, DefaultValidationTimeout)
+}
+
+// ValidateSourcePathWithTimeout checks if source path exists, returning
+// ErrValidationTimeout if the local stat doesn't complete within timeout.
+// A timeout of zero or less waits indefinitely.
+func ValidateSourcePathWithTimeout(path string, timeout time.Duration) error {
 	if path == "" {
 		return &ValidationError{Field: "source", Message: "source path cannot be empty"}
 	}
 
-	// Skip validation for remote paths (contain :)
-	if strings.Contains(path, ":") {
+	// Skip validation for remote paths
+	if IsRemotePath(path) {
 		return nil
 	}
 
 	// Check local path exists
-	_, err := os.Stat(path)
+	_, err := statWithTimeout(path, timeout)
 	if err != nil {
+		if errors.Is(err, ErrValidationTimeout) {
+			return fmt.Errorf("source %s: %w", path, err)
+		}
 		if os.IsNotExist(err) {
 			return &ValidationError{Field: "source", Message: fmt.Sprintf("path does not exist: %s", path)}
 		}
@@ -73,6 +89,55 @@ func ValidateSourcePath(path string) error {
 	return nil
 }
 
+// statWithTimeout runs os.Stat in a goroutine so callers aren't stuck behind
+// an unresponsive mount. The goroutine is left to finish on its own if the
+// timeout fires.
+func statWithTimeout(path string, timeout time.Duration) (os.FileInfo, error) {
+	if timeout <= 0 {
+		return os.Stat(path)
+	}
+
+	type statResult struct {
+		info os.FileInfo
+		err  error
+	}
+	done := make(chan statResult, 1)
+	go func() {
+		info, err := os.Stat(path)
+		done <- statResult{info, err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case r := <-done:
+		return r.info, r.err
+	case <-timer.C:
+		return nil, ErrValidationTimeout
+	}
+}
+
+// ValidateStatsInterval checks that s is a duration rclone's --stats flag
+// accepts, such as "500ms", "5s" or "1m30s". An empty string is allowed and
+// means the default interval.
+func ValidateStatsInterval(s string) error {
+	if s == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return &ValidationError{
+			Field:   "stats_interval",
+			Message: fmt.Sprintf("invalid duration %q (use a unit, e.g. \"500ms\" or \"5s\")", s),
+		}
+	}
+	if d < 0 {
+		return &ValidationError{Field: "stats_interval", Message: fmt.Sprintf("duration cannot be negative: %s", s)}
+	}
+	return nil
+}
+
 // ValidateDestinationPath checks if destination path is accessible
 func ValidateDestinationPath(path string) error {
 	if path == "" {
@@ -80,7 +145,7 @@ func ValidateDestinationPath(path string) error {
 	}
 
 	// For remote paths, we can't easily validate without rclone
-	if strings.Contains(path, ":") {
+	if IsRemotePath(path) {
 		return nil
 	}
 
@@ -128,11 +193,41 @@ func ValidateRemote(ctx context.Context, remoteName string, timeout time.Duratio
 	return nil
 }
 
-// CheckDiskSpace checks if there's enough disk space for a transfer
+// CheckRemoteSpace checks the free space reported by GetRemoteQuota for the
+// remote holding path, returning ErrNoSpaceLeft if it's less than
+// requiredBytes. Remotes that can't report free space return
+// ErrRemoteQuotaUnsupported.
+func CheckRemoteSpace(ctx context.Context, path string, requiredBytes int64) error {
+	quota, err := GetRemoteQuota(ctx, path)
+	if err != nil {
+		return err
+	}
+	if quota.Free == nil {
+		return fmt.Errorf("%w: %s", ErrRemoteQuotaUnsupported, path)
+	}
+	if *quota.Free < requiredBytes {
+		return &ErrNoSpaceLeft{Path: path, Required: requiredBytes, Available: *quota.Free}
+	}
+	return nil
+}
+
+// CheckDiskSpace checks if there's enough disk space for a transfer. See
+// CheckDiskSpaceContext.
 func CheckDiskSpace(path string, requiredBytes int64) error {
-	// For remote paths, skip disk check
-	if strings.Contains(path, ":") {
-		return nil
+	return CheckDiskSpaceContext(context.Background(), path, requiredBytes)
+}
+
+// CheckDiskSpaceContext checks if there's enough space at path for a
+// transfer of requiredBytes, returning ErrNoSpaceLeft if not. Local paths are
+// checked against the filesystem; remote paths with CheckRemoteSpace. Remotes
+// that can't report free space pass, since there's nothing to check against.
+func CheckDiskSpaceContext(ctx context.Context, path string, requiredBytes int64) error {
+	if IsRemotePath(path) {
+		err := CheckRemoteSpace(ctx, path, requiredBytes)
+		if errors.Is(err, ErrRemoteQuotaUnsupported) {
+			return nil
+		}
+		return err
 	}
 
 	// Get absolute path
@@ -159,38 +254,119 @@ func CheckDiskSpace(path string, requiredBytes int64) error {
 	}
 
 	if available < requiredBytes {
-		return fmt.Errorf("insufficient disk space: need %s, have %s",
-			FormattedBytes(requiredBytes),
-			FormattedBytes(available))
+		return &ErrNoSpaceLeft{Path: path, Required: requiredBytes, Available: available}
 	}
 
 	return nil
 }
 
+// rclonePartialExts are the suffixes rclone itself gives files it is still
+// writing
+var rclonePartialExts = []string{".partial", ".rclonepart"}
+
+// partialExts are the suffixes of partial/incomplete downloads left by rclone
+// and common download tools
+var partialExts = append(append([]string(nil), rclonePartialExts...), ".tmp", ".crdownload", ".part")
+
+// PartialFileInfo describes a partial/incomplete download
+type PartialFileInfo struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+	// Extension is the matched suffix, e.g. ".partial"
+	Extension string
+}
+
 // HasPartialFiles checks if a directory contains partial/incomplete downloads
 func HasPartialFiles(dir string) (bool, error) {
-	if dir == "" {
-		return false, nil
+	dir, err := partialDir(dir)
+	if err != nil {
+		return false, err
 	}
+	partials, err := findPartialFiles(dir, partialExts, true)
+	return len(partials) > 0, err
+}
 
-	// Check if directory exists
+// GetPartialFiles returns the partial/incomplete downloads under dir. If dir
+// is a file its directory is searched; a missing dir returns no files.
+// Unreadable entries are skipped.
+func GetPartialFiles(dir string) ([]PartialFileInfo, error) {
+	dir, err := partialDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return findPartialFiles(dir, partialExts, false)
+}
+
+// CleanPartialFiles removes the partial files rclone left under the
+// directory dir (those ending in .partial or .rclonepart) and returns how
+// many were removed. Files with the suffixes in extraExts, e.g. ".part",
+// are removed too; other tools' files are left alone unless asked for. It
+// carries on past files it can't remove and returns the first such error.
+func CleanPartialFiles(dir string, extraExts ...string) (int, error) {
 	info, err := os.Stat(dir)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return false, nil
+			return 0, nil
 		}
-		return false, err
+		return 0, err
+	}
+	if !info.IsDir() {
+		return 0, &ValidationError{Field: "dir", Message: fmt.Sprintf("%s is not a directory", dir)}
+	}
+
+	exts := append([]string(nil), rclonePartialExts...)
+	for _, ext := range extraExts {
+		exts = append(exts, strings.ToLower(ext))
+	}
+
+	partials, err := findPartialFiles(dir, exts, false)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	var firstErr error
+	for _, p := range partials {
+		if err := os.Remove(p.Path); err != nil && !os.IsNotExist(err) {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to remove partial file: %w", err)
+			}
+			continue
+		}
+		removed++
 	}
+	return removed, firstErr
+}
 
+// partialDir returns the directory to search for partial files: dir itself,
+// or its parent if dir is a file. It returns "" if dir doesn't exist.
+func partialDir(dir string) (string, error) {
+	if dir == "" {
+		return "", nil
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
 	if !info.IsDir() {
-		dir = filepath.Dir(dir)
+		return filepath.Dir(dir), nil
 	}
+	return dir, nil
+}
 
-	// Look for partial file extensions
-	partialExts := []string{".partial", ".rclonepart", ".tmp", ".crdownload", ".part"}
+// findPartialFiles walks dir for files ending in one of exts, stopping at
+// the first if first is set
+func findPartialFiles(dir string, exts []string, first bool) ([]PartialFileInfo, error) {
+	if dir == "" {
+		return nil, nil
+	}
 
-	var hasPartial bool
-	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+	var partials []PartialFileInfo
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // Skip errors
 		}
@@ -199,23 +375,32 @@ func HasPartialFiles(dir string) (bool, error) {
 			return nil
 		}
 
-		for _, ext := range partialExts {
-			if strings.HasSuffix(strings.ToLower(info.Name()), ext) {
-				hasPartial = true
-				return filepath.SkipAll // Found one, stop walking
+		name := strings.ToLower(info.Name())
+		for _, ext := range exts {
+			if strings.HasSuffix(name, ext) {
+				partials = append(partials, PartialFileInfo{
+					Path:      path,
+					Size:      info.Size(),
+					ModTime:   info.ModTime(),
+					Extension: ext,
+				})
+				if first {
+					return filepath.SkipAll // Found one, stop walking
+				}
+				break
 			}
 		}
 
 		return nil
 	})
 
-	return hasPartial, err
+	return partials, err
 }
 
 // GetFileSize returns the size of a local or remote file
 func GetFileSize(ctx context.Context, path string) (int64, error) {
 	// For local files
-	if !strings.Contains(path, ":") {
+	if !IsRemotePath(path) {
 		info, err := os.Stat(path)
 		if err != nil {
 			return 0, err
@@ -249,3 +434,132 @@ func GetFileSize(ctx context.Context, path string) (int64, error) {
 
 	return 0, fmt.Errorf("failed to parse size from rclone output")
 }
+
+// ConfigValidationReport is the result of ValidateRcloneConfig
+type ConfigValidationReport struct {
+	// ValidRemotes and InvalidRemotes list remote names in config file order
+	ValidRemotes   []string
+	InvalidRemotes []string
+	// Errors explains why each invalid remote failed
+	Errors map[string]error
+	// Warnings are problems that don't stop rclone from using the config,
+	// such as duplicate sections or notices rclone logged while reading it
+	Warnings []string
+}
+
+// configSection is a remote parsed from an rclone config file
+type configSection struct {
+	name   string
+	params map[string]string
+}
+
+// ValidateRcloneConfig checks the rclone config file at configPath before it
+// is deployed. Every remote must have a type and be readable by `rclone
+// config show`. The returned error is non-nil if the file can't be read or
+// any remote is invalid; the report says which.
+func ValidateRcloneConfig(ctx context.Context, configPath string) (*ConfigValidationReport, error) {
+	return validateRcloneConfig(ctx, configPath, false)
+}
+
+// ValidateRcloneConfigStrict is ValidateRcloneConfig that also fails when
+// the report has warnings
+func ValidateRcloneConfigStrict(ctx context.Context, configPath string) (*ConfigValidationReport, error) {
+	return validateRcloneConfig(ctx, configPath, true)
+}
+
+func validateRcloneConfig(ctx context.Context, configPath string, strict bool) (*ConfigValidationReport, error) {
+	if configPath == "" {
+		return nil, &ValidationError{Field: "config", Message: "config path cannot be empty"}
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	report := &ConfigValidationReport{Errors: make(map[string]error)}
+
+	sections, encrypted, warnings := parseConfigSections(string(data))
+	report.Warnings = append(report.Warnings, warnings...)
+	if encrypted {
+		// The remotes can only be read through rclone, which needs
+		// RCLONE_CONFIG_PASS in the environment to decrypt them
+		output, err := runRclone(ctx, "listremotes", "--config", configPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list remotes in encrypted config: %w", err)
+		}
+		for _, line := range strings.Fields(string(output)) {
+			sections = append(sections, configSection{name: strings.TrimSuffix(line, ":")})
+		}
+	}
+
+	for _, section := range sections {
+		err := checkConfigSection(ctx, configPath, section, !encrypted, report)
+		if err != nil {
+			report.InvalidRemotes = append(report.InvalidRemotes, section.name)
+			report.Errors[section.name] = err
+		} else {
+			report.ValidRemotes = append(report.ValidRemotes, section.name)
+		}
+	}
+
+	if len(report.InvalidRemotes) > 0 {
+		return report, fmt.Errorf("invalid remotes in %s: %s", configPath, strings.Join(report.InvalidRemotes, ", "))
+	}
+	if strict && len(report.Warnings) > 0 {
+		return report, fmt.Errorf("config %s has warnings: %s", configPath, strings.Join(report.Warnings, "; "))
+	}
+	return report, nil
+}
+
+// checkConfigSection validates one remote, adding any rclone notices to the
+// report's warnings
+func checkConfigSection(ctx context.Context, configPath string, section configSection, checkType bool, report *ConfigValidationReport) error {
+	if checkType && section.params["type"] == "" {
+		return fmt.Errorf("remote %s has no type", section.name)
+	}
+
+	output, err := runRcloneCombined(ctx, "config", "show", section.name+":", "--config", configPath)
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if ev, ok := parseLogLine(line); ok && ev.Level != "INFO" && ev.Level != "DEBUG" {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%s: %s", section.name, ev.Message))
+		}
+	}
+	return nil
+}
+
+// parseConfigSections reads the remotes from an rclone INI config file. It
+// reports whether the file is encrypted, in which case nothing else can be
+// read from it, along with warnings about duplicate sections.
+func parseConfigSections(data string) (sections []configSection, encrypted bool, warnings []string) {
+	index := make(map[string]int)
+	current := -1
+
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+			continue
+		case strings.HasPrefix(line, "RCLONE_ENCRYPT_V0:"):
+			return nil, true, nil
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if i, exists := index[name]; exists {
+				warnings = append(warnings, fmt.Sprintf("duplicate section [%s]", name))
+				current = i
+				continue
+			}
+			index[name] = len(sections)
+			current = len(sections)
+			sections = append(sections, configSection{name: name, params: make(map[string]string)})
+		case current >= 0:
+			if key, value, ok := strings.Cut(line, "="); ok {
+				sections[current].params[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			}
+		}
+	}
+	return sections, false, warnings
+}
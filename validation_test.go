@@ -0,0 +1,91 @@
+package rclonelib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseConfigSections(t *testing.T) {
+	data := `# comment
+[mys3]
+type = s3
+provider = AWS
+
+[local]
+type = local
+
+[mys3]
+region = eu-west-1
+
+[broken]
+key = value
+`
+	sections, encrypted, warnings := parseConfigSections(data)
+	if encrypted {
+		t.Fatal("plain config reported as encrypted")
+	}
+	if len(sections) != 3 {
+		t.Fatalf("expected 3 sections, got %d: %+v", len(sections), sections)
+	}
+	if s := sections[0]; s.name != "mys3" || s.params["type"] != "s3" || s.params["region"] != "eu-west-1" {
+		t.Errorf("duplicate section not merged: %+v", s)
+	}
+	if sections[2].params["type"] != "" {
+		t.Errorf("broken remote should have no type: %+v", sections[2])
+	}
+	if len(warnings) != 1 {
+		t.Errorf("expected a duplicate-section warning, got %v", warnings)
+	}
+
+	if _, encrypted, _ := parseConfigSections("# Encrypted rclone configuration File\n\nRCLONE_ENCRYPT_V0:\nabc"); !encrypted {
+		t.Error("encrypted config not detected")
+	}
+}
+
+func TestPartialFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"done.txt", "a.iso.partial", "sub/b.PART"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("data"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	partials, err := GetPartialFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(partials) != 2 {
+		t.Fatalf("expected 2 partial files, got %+v", partials)
+	}
+	if partials[0].Extension != ".partial" || partials[0].Size != 4 {
+		t.Errorf("unexpected partial file: %+v", partials[0])
+	}
+
+	if _, err := CleanPartialFiles(filepath.Join(dir, "done.txt")); err == nil {
+		t.Error("CleanPartialFiles accepted a file")
+	}
+
+	// Only rclone's own partial files go unless others are asked for.
+	removed, err := CleanPartialFiles(dir)
+	if err != nil || removed != 1 {
+		t.Fatalf("CleanPartialFiles = %d, %v", removed, err)
+	}
+	if has, _ := HasPartialFiles(dir); !has {
+		t.Error(".part file removed without opting in")
+	}
+	removed, err = CleanPartialFiles(dir, ".part")
+	if err != nil || removed != 1 {
+		t.Fatalf("CleanPartialFiles(.part) = %d, %v", removed, err)
+	}
+	if has, _ := HasPartialFiles(dir); has {
+		t.Error("partial files left after cleaning")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "done.txt")); err != nil {
+		t.Errorf("complete file removed: %v", err)
+	}
+}
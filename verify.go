@@ -0,0 +1,151 @@
+package rclonelib
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// VerifyOptions configures Executor.Verify
+type VerifyOptions struct {
+	// ChecksumOnly requires the comparison to use hashes, failing with an
+	// error if source and destination have no hash type in common instead
+	// of falling back to sizes
+	ChecksumOnly bool
+	// SizeOnly compares sizes only (--size-only)
+	SizeOnly bool
+	// DownloadFlag compares file contents by downloading both sides
+	// (--download), for backends without usable hashes
+	DownloadFlag bool
+	// CommonFlags supplies include/exclude patterns and other filters
+	CommonFlags CommonFlags
+	// TransferID, if set, tracks the verification as a transfer in the
+	// executor's manager so it shows up in the UI. It is added if it doesn't
+	// exist yet.
+	TransferID string
+}
+
+// VerifyResult is the outcome of Executor.Verify
+type VerifyResult struct {
+	// Missing are files in the source but not the destination
+	Missing []string
+	// Extra are files in the destination but not the source
+	Extra []string
+	// Differ are files whose contents differ, or that couldn't be compared
+	Differ []string
+	// Match is the number of identical files
+	Match int
+	// Error is a ClassifiedError of type ErrorTypeVerificationFailed when
+	// anything is missing, extra or different, and nil otherwise
+	Error error
+}
+
+// Verify checks that destination holds an identical copy of source using
+// `rclone check`, e.g. after a transfer or to audit existing data.
+// Mismatches are reported in the result, with Error set; the returned error
+// means the check itself couldn't run.
+func (e *Executor) Verify(ctx context.Context, source, destination string, opts VerifyOptions) (*VerifyResult, error) {
+	if opts.ChecksumOnly && (opts.SizeOnly || opts.DownloadFlag) {
+		return nil, &ValidationError{Field: "verify", Message: "ChecksumOnly cannot be combined with SizeOnly or DownloadFlag"}
+	}
+	if opts.SizeOnly && opts.DownloadFlag {
+		return nil, &ValidationError{Field: "verify", Message: "SizeOnly and DownloadFlag cannot both be set"}
+	}
+
+	id := opts.TransferID
+	if id != "" {
+		if _, exists := e.manager.Get(id); !exists {
+			e.manager.Add(id, source, destination)
+		}
+		if err := e.manager.Start(id); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := e.verify(ctx, source, destination, opts)
+	if id != "" {
+		switch {
+		case err != nil:
+			e.manager.Fail(id, err)
+		case result.Error != nil:
+			e.manager.Fail(id, result.Error)
+		default:
+			e.manager.Complete(id)
+		}
+	}
+	return result, err
+}
+
+func (e *Executor) verify(ctx context.Context, source, destination string, opts VerifyOptions) (*VerifyResult, error) {
+	if opts.ChecksumOnly {
+		if err := requireCommonHash(ctx, source, destination); err != nil {
+			return nil, err
+		}
+	}
+
+	check, err := e.Check(CheckOptions{
+		Source:      source,
+		Destination: destination,
+		Download:    opts.DownloadFlag,
+		SizeOnly:    opts.SizeOnly,
+		Flags:       opts.CommonFlags.ToFlags(),
+		Context:     ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &VerifyResult{Match: check.Matches}
+	for _, line := range check.CombinedLines {
+		switch line.Status {
+		case CheckMissingOnDst:
+			result.Missing = append(result.Missing, line.Path)
+		case CheckMissingOnSrc:
+			result.Extra = append(result.Extra, line.Path)
+		case CheckDiffer, CheckError:
+			result.Differ = append(result.Differ, line.Path)
+		}
+	}
+
+	if len(result.Missing) > 0 || len(result.Extra) > 0 || len(result.Differ) > 0 {
+		result.Error = &ClassifiedError{
+			Type: ErrorTypeVerificationFailed,
+			Err: fmt.Errorf("%s does not match %s: %d missing, %d extra, %d differ",
+				destination, source, len(result.Missing), len(result.Extra), len(result.Differ)),
+		}
+	}
+	return result, nil
+}
+
+// requireCommonHash returns an error unless source and destination support
+// a hash type in common, so rclone check won't fall back to sizes
+func requireCommonHash(ctx context.Context, source, destination string) error {
+	srcHashes, err := SupportedChecksums(ctx, source)
+	if err != nil {
+		return err
+	}
+	dstHashes, err := SupportedChecksums(ctx, destination)
+	if err != nil {
+		return err
+	}
+
+	for _, h := range srcHashes {
+		for _, d := range dstHashes {
+			if h == d {
+				return nil
+			}
+		}
+	}
+	return &ValidationError{
+		Field: "verify",
+		Message: fmt.Sprintf("no common hash type (source: %s; destination: %s)",
+			hashList(srcHashes), hashList(dstHashes)),
+	}
+}
+
+func hashList(hashes []string) string {
+	if len(hashes) == 0 {
+		return "none"
+	}
+	return strings.Join(hashes, ", ")
+}
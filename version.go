@@ -0,0 +1,400 @@
+package rclonelib
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SelfUpdateOptions configures SelfUpdate
+type SelfUpdateOptions struct {
+	// Beta installs the latest beta rather than the latest stable release
+	Beta bool
+	// Check only reports whether an update is available
+	Check bool
+	// Version installs a specific version (e.g. "1.65.0") instead of the latest
+	Version string
+}
+
+// UpdateResult describes the outcome of SelfUpdate
+type UpdateResult struct {
+	Available        bool
+	CurrentVersion   string
+	AvailableVersion string
+}
+
+// SelfUpdate checks for, and unless opts.Check is set installs, a newer rclone
+// binary using `rclone selfupdate`. Before updating it verifies that the
+// binary on PATH is writable so a permissions problem is reported up front
+// rather than halfway through the upgrade.
+func SelfUpdate(ctx context.Context, opts SelfUpdateOptions) (*UpdateResult, error) {
+	output, err := runRclone(ctx, "selfupdate", "--check")
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for rclone update: %w", err)
+	}
+
+	check := parseVersionCheck(string(output))
+	result := &UpdateResult{
+		CurrentVersion:   check.yours,
+		AvailableVersion: check.latest,
+	}
+	if opts.Beta {
+		result.AvailableVersion = check.beta
+	}
+	if opts.Version != "" {
+		result.AvailableVersion = strings.TrimPrefix(opts.Version, "v")
+	}
+	result.Available = result.AvailableVersion != "" &&
+		compareVersions(result.AvailableVersion, result.CurrentVersion) != 0
+
+	if opts.Check || !result.Available {
+		return result, nil
+	}
+
+	if err := checkRcloneWritable(); err != nil {
+		return result, err
+	}
+
+	args := []string{"selfupdate"}
+	if opts.Beta {
+		args = append(args, "--beta")
+	}
+	if opts.Version != "" {
+		args = append(args, "--version", opts.Version)
+	}
+
+	if _, err := runRclone(ctx, args...); err != nil {
+		return result, fmt.Errorf("failed to update rclone: %w", err)
+	}
+
+	return result, nil
+}
+
+// checkRcloneWritable verifies the rclone binary on PATH can be replaced
+func checkRcloneWritable() error {
+	path, err := exec.LookPath("rclone")
+	if err != nil {
+		return fmt.Errorf("rclone not found in PATH: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("rclone binary is not writable: %w", err)
+	}
+	return f.Close()
+}
+
+// versionCheck holds the versions reported by `rclone selfupdate --check`
+// and `rclone version --check`
+type versionCheck struct {
+	yours  string
+	latest string
+	beta   string
+}
+
+// parseVersionCheck parses output of the form:
+//
+//	yours:  1.53.3
+//	latest: 1.55.0  (released 2021-03-31)
+//	beta:   1.56.0-beta.5392.b8e90b38d  (released 2021-04-01)
+func parseVersionCheck(output string) versionCheck {
+	var check versionCheck
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		version := strings.TrimPrefix(fields[0], "v")
+
+		switch strings.TrimSpace(key) {
+		case "yours":
+			check.yours = version
+		case "latest":
+			check.latest = version
+		case "beta":
+			check.beta = version
+		}
+	}
+	return check
+}
+
+// VersionCheckResult reports whether a newer rclone release is available
+type VersionCheckResult struct {
+	Current         string
+	Latest          string
+	UpdateAvailable bool
+}
+
+// versionCheckTTL is how long CheckForNewerVersion reuses a result
+const versionCheckTTL = 24 * time.Hour
+
+// versionCheckCache holds the last CheckForNewerVersion result so repeated
+// calls don't hit rclone's update endpoint
+var versionCheckCache struct {
+	mu      sync.Mutex
+	result  *VersionCheckResult
+	expires time.Time
+}
+
+// CheckForNewerVersion asks rclone whether a newer stable release exists,
+// using `rclone version --check`. The answer is cached for 24 hours per
+// process.
+func CheckForNewerVersion(ctx context.Context) (*VersionCheckResult, error) {
+	versionCheckCache.mu.Lock()
+	defer versionCheckCache.mu.Unlock()
+
+	if versionCheckCache.result != nil && time.Now().Before(versionCheckCache.expires) {
+		cached := *versionCheckCache.result
+		return &cached, nil
+	}
+
+	output, err := runRclone(ctx, "version", "--check")
+	if err != nil {
+		return nil, fmt.Errorf("failed to check rclone version: %w", err)
+	}
+
+	check := parseVersionCheck(string(output))
+	if check.yours == "" {
+		return nil, fmt.Errorf("failed to parse rclone version check output")
+	}
+
+	result := &VersionCheckResult{
+		Current:         check.yours,
+		Latest:          check.latest,
+		UpdateAvailable: check.latest != "" && compareVersions(check.latest, check.yours) > 0,
+	}
+
+	versionCheckCache.result = result
+	versionCheckCache.expires = time.Now().Add(versionCheckTTL)
+
+	cached := *result
+	return &cached, nil
+}
+
+// compareVersions compares two rclone version strings ("1.65.0", "v1.66.0",
+// "1.67.0-beta.7890.abc") and returns -1, 0 or 1. Missing components count as
+// zero, and a pre-release sorts before the release it precedes.
+func compareVersions(a, b string) int {
+	aMain, aPre, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(a), "v"), "-")
+	bMain, bPre, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(b), "v"), "-")
+
+	aParts := strings.Split(aMain, ".")
+	bParts := strings.Split(bMain, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var x, y int
+		if i < len(aParts) {
+			x, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			y, _ = strconv.Atoi(bParts[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	case aPre < bPre:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// VersionConstraint is a range of acceptable rclone versions, parsed by
+// NewVersionConstraint
+type VersionConstraint struct {
+	min, max                   string
+	minInclusive, maxInclusive bool
+	expr                       string
+}
+
+// NewVersionConstraint parses a version constraint. Accepted forms are a
+// comparison (">= 1.60.0", "> 1.60", "<= 1.65.0", "< 2.0.0", "= 1.65.0" or
+// just "1.65.0"), an inclusive range ("1.60.0 - 1.65.0"), or several
+// comparisons separated by commas (">= 1.60.0, < 2.0.0").
+func NewVersionConstraint(expr string) (VersionConstraint, error) {
+	vc := VersionConstraint{expr: strings.TrimSpace(expr)}
+	if vc.expr == "" {
+		return vc, &ValidationError{Field: "constraint", Message: "constraint cannot be empty"}
+	}
+
+	if lo, hi, ok := strings.Cut(vc.expr, " - "); ok {
+		vc.min, vc.minInclusive = strings.TrimPrefix(strings.TrimSpace(lo), "v"), true
+		vc.max, vc.maxInclusive = strings.TrimPrefix(strings.TrimSpace(hi), "v"), true
+		if !isVersion(vc.min) || !isVersion(vc.max) {
+			return vc, &ValidationError{Field: "constraint", Message: "invalid version range: " + vc.expr}
+		}
+		return vc, nil
+	}
+
+	for _, part := range strings.Split(vc.expr, ",") {
+		part = strings.TrimSpace(part)
+
+		op := ""
+		for _, candidate := range []string{">=", "<=", "==", ">", "<", "="} {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				break
+			}
+		}
+		version := strings.TrimPrefix(strings.TrimSpace(part[len(op):]), "v")
+		if !isVersion(version) {
+			return vc, &ValidationError{Field: "constraint", Message: "invalid version constraint: " + part}
+		}
+
+		switch op {
+		case ">=":
+			vc.min, vc.minInclusive = version, true
+		case ">":
+			vc.min, vc.minInclusive = version, false
+		case "<=":
+			vc.max, vc.maxInclusive = version, true
+		case "<":
+			vc.max, vc.maxInclusive = version, false
+		default:
+			vc.min, vc.minInclusive = version, true
+			vc.max, vc.maxInclusive = version, true
+		}
+	}
+
+	return vc, nil
+}
+
+// isVersion reports whether s looks like a dotted version number
+func isVersion(s string) bool {
+	main, _, _ := strings.Cut(s, "-")
+	if main == "" {
+		return false
+	}
+	for _, part := range strings.Split(main, ".") {
+		if _, err := strconv.Atoi(part); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the expression the constraint was parsed from
+func (vc VersionConstraint) String() string {
+	return vc.expr
+}
+
+// Allows reports whether version ("1.65.0", "v1.65.0" or "rclone v1.65.0")
+// satisfies the constraint
+func (vc VersionConstraint) Allows(version string) bool {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "rclone ")
+
+	if vc.min != "" {
+		c := compareVersions(version, vc.min)
+		if c < 0 || (c == 0 && !vc.minInclusive) {
+			return false
+		}
+	}
+	if vc.max != "" {
+		c := compareVersions(version, vc.max)
+		if c > 0 || (c == 0 && !vc.maxInclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// Satisfied reports whether the installed rclone satisfies the constraint
+func (vc VersionConstraint) Satisfied(ctx context.Context) (bool, error) {
+	version, err := GetRcloneVersion(ctx)
+	if err != nil {
+		return false, err
+	}
+	return vc.Allows(version), nil
+}
+
+// RequireVersion returns an error unless the installed rclone satisfies
+// constraint (see NewVersionConstraint). Intended for startup checks.
+func RequireVersion(ctx context.Context, constraint string) error {
+	vc, err := NewVersionConstraint(constraint)
+	if err != nil {
+		return err
+	}
+
+	version, err := GetRcloneVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if !vc.Allows(version) {
+		return fmt.Errorf("%s does not satisfy version constraint %q", version, vc.expr)
+	}
+	return nil
+}
+
+// featureVersions maps the features HasFeature knows about to the first
+// rclone release that has them
+var featureVersions = map[string]string{
+	"serve-docker":   "1.56",
+	"hasher":         "1.57",
+	"bisync":         "1.58",
+	"metadata":       "1.59",
+	"combine":        "1.59",
+	"serve-s3":       "1.65",
+	"serve-nfs":      "1.65",
+	"nfsmount":       "1.65",
+	"name-transform": "1.70",
+}
+
+// HasFeature reports whether the installed rclone is new enough for
+// feature, e.g. "bisync" or "metadata". It returns a ValidationError for
+// features it doesn't know about.
+func HasFeature(ctx context.Context, feature string) (bool, error) {
+	minVersion, ok := featureVersions[feature]
+	if !ok {
+		return false, &ValidationError{Field: "feature", Message: fmt.Sprintf("unknown feature %q", feature)}
+	}
+
+	version, err := GetRcloneVersion(ctx)
+	if err != nil {
+		return false, err
+	}
+	return featureSupported(version, minVersion), nil
+}
+
+// RequireFeature returns an error wrapping ErrFeatureNotSupported, naming
+// the minimum version, unless the installed rclone supports feature.
+// Intended for checks before running commands that need a newer rclone.
+func RequireFeature(ctx context.Context, feature string) error {
+	supported, err := HasFeature(ctx, feature)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return fmt.Errorf("%w: %s requires rclone %s or later", ErrFeatureNotSupported, feature, featureVersions[feature])
+	}
+	return nil
+}
+
+// featureSupported reports whether version is at least minVersion
+func featureSupported(version, minVersion string) bool {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "rclone ")
+	return compareVersions(version, minVersion) >= 0
+}
@@ -0,0 +1,72 @@
+package rclonelib
+
+import (
+	"context"
+	"testing"
+)
+
+func TestVersionConstraint_Allows(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{">= 1.60.0", "1.60.0", true},
+		{">= 1.60.0", "v1.65.2", true},
+		{">= 1.60.0", "1.59.9", false},
+		{"> 1.60.0", "1.60.0", false},
+		{"> 1.60", "1.60.1", true},
+		{"< 2.0.0", "1.99.0", true},
+		{"< 2.0.0", "2.0.0", false},
+		{"<= 1.65.0", "1.65.0", true},
+		{"1.65.0", "1.65.0", true},
+		{"= 1.65.0", "1.65.1", false},
+		{"1.60.0 - 1.65.0", "1.60.0", true},
+		{"1.60.0 - 1.65.0", "1.65.0", true},
+		{"1.60.0 - 1.65.0", "1.66.0", false},
+		{">= 1.60.0, < 2.0.0", "1.70.0", true},
+		{">= 1.60.0, < 2.0.0", "2.1.0", false},
+		{">= 1.66.0", "1.66.0-beta.7890.abc", false},
+		{">= 1.60.0", "rclone v1.65.0", true},
+	}
+
+	for _, tt := range tests {
+		vc, err := NewVersionConstraint(tt.constraint)
+		if err != nil {
+			t.Fatalf("NewVersionConstraint(%q): %v", tt.constraint, err)
+		}
+		if got := vc.Allows(tt.version); got != tt.want {
+			t.Errorf("%q allows %q = %v, want %v", tt.constraint, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestNewVersionConstraint_Invalid(t *testing.T) {
+	for _, expr := range []string{"", ">= ", "~> 1.2", "1.60.0 - latest", ">= abc"} {
+		if _, err := NewVersionConstraint(expr); err == nil {
+			t.Errorf("expected error for %q", expr)
+		}
+	}
+}
+
+func TestFeatureSupported(t *testing.T) {
+	tests := []struct {
+		version, min string
+		want         bool
+	}{
+		{"rclone v1.58.0", "1.58", true},
+		{"v1.65.2", "1.58", true},
+		{"1.57.0", "1.58", false},
+		{"rclone v1.62.0", "1.65", false},
+	}
+
+	for _, tt := range tests {
+		if got := featureSupported(tt.version, tt.min); got != tt.want {
+			t.Errorf("featureSupported(%q, %q) = %v, want %v", tt.version, tt.min, got, tt.want)
+		}
+	}
+
+	if _, err := HasFeature(context.Background(), "teleport"); err == nil {
+		t.Error("expected an error for an unknown feature")
+	}
+}
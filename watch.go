@@ -0,0 +1,182 @@
+package rclonelib
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// File change operations reported in FileChangeEvent.Op
+const (
+	FileCreate = "Create"
+	FileWrite  = "Write"
+	FileRemove = "Remove"
+	FileRename = "Rename"
+)
+
+// FileChangeEvent describes a change to a file under a watched directory
+type FileChangeEvent struct {
+	// Op is one of FileCreate, FileWrite, FileRemove or FileRename
+	Op   string
+	Path string
+}
+
+// FileWatcher reports changes to files under a local directory, including
+// its subdirectories
+type FileWatcher struct {
+	dir string
+}
+
+// NewFileWatcher returns a watcher for the local directory dir
+func NewFileWatcher(dir string) *FileWatcher {
+	return &FileWatcher{dir: dir}
+}
+
+// Watch calls fn for every change under the directory until ctx is done. It
+// blocks, so run it in its own goroutine. fn is called from that goroutine
+// one event at a time.
+func (w *FileWatcher) Watch(ctx context.Context, fn func(event FileChangeEvent)) error {
+	return w.watch(ctx, fn, nil)
+}
+
+// watch implements Watch, sending the result of setting up the watch on
+// ready (if not nil) before waiting for events
+func (w *FileWatcher) watch(ctx context.Context, fn func(event FileChangeEvent), ready chan<- error) error {
+	watcher, err := w.start()
+	if ready != nil {
+		ready <- err
+	}
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("failed watching %s: %w", w.dir, err)
+		case ev, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			// fsnotify doesn't recurse, so follow new subdirectories
+			if ev.Has(fsnotify.Create) {
+				if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+					addTree(watcher, ev.Name)
+				}
+			}
+
+			if op := fileChangeOp(ev.Op); op != "" {
+				fn(FileChangeEvent{Op: op, Path: ev.Name})
+			}
+		}
+	}
+}
+
+// start creates an fsnotify watcher covering the directory tree
+func (w *FileWatcher) start() (*fsnotify.Watcher, error) {
+	if w.dir == "" {
+		return nil, &ValidationError{Field: "dir", Message: "directory cannot be empty"}
+	}
+	if IsRemotePath(w.dir) {
+		return nil, &ValidationError{Field: "dir", Message: "only local directories can be watched"}
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watcher: %w", err)
+	}
+	if err := watcher.Add(w.dir); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("failed to watch %s: %w", w.dir, err)
+	}
+	addTree(watcher, w.dir)
+	return watcher, nil
+}
+
+// addTree adds every directory below root to watcher. Directories that
+// can't be watched are skipped.
+func addTree(watcher *fsnotify.Watcher, root string) {
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() && path != root {
+			watcher.Add(path)
+		}
+		return nil
+	})
+}
+
+// fileChangeOp maps an fsnotify operation to a FileChangeEvent.Op, or ""
+// for changes that don't affect file contents, such as chmod
+func fileChangeOp(op fsnotify.Op) string {
+	switch {
+	case op.Has(fsnotify.Create):
+		return FileCreate
+	case op.Has(fsnotify.Write):
+		return FileWrite
+	case op.Has(fsnotify.Remove):
+		return FileRemove
+	case op.Has(fsnotify.Rename):
+		return FileRename
+	}
+	return ""
+}
+
+// WatchSource watches a transfer's source with watcher for as long as the
+// transfer hasn't finished, emitting a TransferEvent with SourceChanged set
+// for each change made while it is in progress. The caller decides whether
+// to restart, ignore or abort the transfer.
+func (m *Manager) WatchSource(id string, watcher *FileWatcher) error {
+	if _, exists := m.Get(id); !exists {
+		return fmt.Errorf("%w: %s", ErrTransferNotFound, id)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ready := make(chan error, 1)
+
+	go func() {
+		defer cancel()
+		watcher.watch(ctx, func(ev FileChangeEvent) {
+			m.recordSourceChanged(id, ev)
+		}, ready)
+	}()
+
+	if err := <-ready; err != nil {
+		return err
+	}
+
+	// Stop watching once the transfer is done
+	go func() {
+		defer cancel()
+		for {
+			m.mu.RLock()
+			t, exists := m.transfers[id]
+			done := !exists || t.IsTerminal()
+			changed := m.changed
+			m.mu.RUnlock()
+
+			if done {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-changed:
+			}
+		}
+	}()
+
+	return nil
+}
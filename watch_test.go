@@ -0,0 +1,45 @@
+package rclonelib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchSource_EmitsWhileInProgress(t *testing.T) {
+	dir := t.TempDir()
+
+	m := NewManager()
+	m.Add("t1", dir, "remote:dst")
+	m.Start("t1")
+
+	events := m.Subscribe()
+	defer m.Unsubscribe(events)
+
+	if err := m.WatchSource("t1", NewFileWatcher(dir)); err != nil {
+		t.Fatalf("WatchSource: %v", err)
+	}
+
+	path := filepath.Join(dir, "new.txt")
+	if err := os.WriteFile(path, []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev := <-events:
+			if ev.SourceChanged == nil {
+				continue
+			}
+			if ev.TransferID != "t1" || ev.SourceChanged.Path != path {
+				t.Errorf("unexpected event %+v", ev.SourceChanged)
+			}
+			m.Complete("t1")
+			return
+		case <-timeout:
+			t.Fatal("no SourceChanged event received")
+		}
+	}
+}
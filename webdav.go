@@ -0,0 +1,137 @@
+package rclonelib
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// WebDAVOptions configures NewWebDAVServer
+type WebDAVOptions struct {
+	// Addr is the listen address (default: 127.0.0.1:8080)
+	Addr string
+	// User and Password enable HTTP basic authentication
+	User     string
+	Password string
+	// ReadOnly rejects uploads, deletes and other modifications
+	ReadOnly bool
+}
+
+// WebDAVServer serves a remote over WebDAV using `rclone serve webdav`, so
+// it can be mounted by operating systems and file managers or reached with
+// WebDAVClient
+type WebDAVServer struct {
+	*ServeServer
+}
+
+// NewWebDAVServer returns a WebDAV server for remote. Call Start to run it.
+// rclone serves plain HTTP, so set User and Password and keep Addr on
+// localhost unless the network is trusted.
+//
+// The password is handed to rclone as RCLONE_PASS rather than --pass, so it
+// doesn't show up in the process list.
+func NewWebDAVServer(remote string, opts WebDAVOptions) *WebDAVServer {
+	var flags []string
+	if opts.User != "" {
+		flags = append(flags, "--user", opts.User)
+	}
+	if opts.ReadOnly {
+		flags = append(flags, "--read-only")
+	}
+	server := NewServeServer(ServeWebDAV, remote, opts.Addr, flags...)
+	if opts.Password != "" {
+		server.Env = map[string]string{"RCLONE_PASS": opts.Password}
+	}
+	return &WebDAVServer{ServeServer: server}
+}
+
+// WebDAVClient is a minimal WebDAV client for reading and writing single
+// files
+type WebDAVClient struct {
+	baseURL  string
+	user     string
+	password string
+	client   *http.Client
+}
+
+// NewWebDAVClient returns a client for the WebDAV server at url, using basic
+// authentication when user is set
+func NewWebDAVClient(url, user, password string) *WebDAVClient {
+	return &WebDAVClient{
+		baseURL:  strings.TrimSuffix(url, "/"),
+		user:     user,
+		password: password,
+		client:   http.DefaultClient,
+	}
+}
+
+// Get opens the file at path for reading. The caller must close it. Missing
+// files return ErrNotFound.
+func (c *WebDAVClient) Get(ctx context.Context, path string) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, http.MethodGet, path, nil, -1)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Put uploads r to path, replacing any existing file. Pass size -1 if it
+// isn't known.
+func (c *WebDAVClient) Put(ctx context.Context, path string, r io.Reader, size int64) error {
+	resp, err := c.do(ctx, http.MethodPut, path, r, size)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Delete removes the file at path
+func (c *WebDAVClient) Delete(ctx context.Context, path string) error {
+	resp, err := c.do(ctx, http.MethodDelete, path, nil, -1)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do sends a request and turns non-2xx responses into errors
+func (c *WebDAVClient) do(ctx context.Context, method, path string, body io.Reader, size int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+"/"+escapePath(path), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create WebDAV request: %w", err)
+	}
+	if size >= 0 {
+		req.ContentLength = size
+	}
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("WebDAV %s %s failed: %w", method, path, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, path)
+		}
+		return nil, fmt.Errorf("WebDAV %s %s failed: %s", method, path, resp.Status)
+	}
+	return resp, nil
+}
+
+// escapePath escapes each segment of a slash-separated path for use in a
+// URL, so names containing "?", "#", "%" or spaces reach the right resource
+func escapePath(path string) string {
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	return strings.Join(segments, "/")
+}
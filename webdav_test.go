@@ -0,0 +1,121 @@
+package rclonelib
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeWebDAV is a tiny in-memory GET/PUT/DELETE server standing in for
+// `rclone serve webdav`
+func fakeWebDAV(t *testing.T, user, pass string) *httptest.Server {
+	var mu sync.Mutex
+	files := map[string][]byte{}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != user || p != pass {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.Method {
+		case http.MethodGet:
+			data, ok := files[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(data)
+		case http.MethodPut:
+			data, _ := io.ReadAll(r.Body)
+			files[r.URL.Path] = data
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodDelete:
+			delete(files, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+}
+
+func TestWebDAVClient_PutGetDelete(t *testing.T) {
+	srv := fakeWebDAV(t, "alice", "secret")
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := NewWebDAVClient(srv.URL+"/", "alice", "secret")
+
+	content := "hello webdav"
+	if err := client.Put(ctx, "dir/file.txt", strings.NewReader(content), int64(len(content))); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	body, err := client.Get(ctx, "/dir/file.txt")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != content {
+		t.Errorf("expected %q, got %q", content, data)
+	}
+
+	if err := client.Delete(ctx, "dir/file.txt"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := client.Get(ctx, "dir/file.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestWebDAVClient_BadCredentials(t *testing.T) {
+	srv := fakeWebDAV(t, "alice", "secret")
+	defer srv.Close()
+
+	client := NewWebDAVClient(srv.URL, "alice", "wrong")
+	if _, err := client.Get(context.Background(), "file.txt"); err == nil {
+		t.Fatal("expected an error with bad credentials")
+	}
+}
+
+func TestWebDAVClient_EscapesPath(t *testing.T) {
+	srv := fakeWebDAV(t, "alice", "secret")
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := NewWebDAVClient(srv.URL, "alice", "secret")
+
+	name := "dir/50% off? #1 draft.txt"
+	if err := client.Put(ctx, name, strings.NewReader("x"), 1); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, err := client.Get(ctx, "dir/50% off"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("name was cut short at a special character: %v", err)
+	}
+	body, err := client.Get(ctx, name)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	body.Close()
+}
+
+func TestNewWebDAVServer_PasswordNotOnCommandLine(t *testing.T) {
+	s := NewWebDAVServer("remote:", WebDAVOptions{User: "alice", Password: "secret"})
+	for _, f := range s.Flags {
+		if f == "--pass" || f == "secret" {
+			t.Errorf("password on the command line: %v", s.Flags)
+		}
+	}
+	if s.Env["RCLONE_PASS"] != "secret" {
+		t.Errorf("Env = %v, want RCLONE_PASS", s.Env)
+	}
+}